@@ -5,6 +5,7 @@ import (
 	"math"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/apex/log"
@@ -25,7 +26,8 @@ var (
 	wechatClient      *wechat.Client           = wechat.NewClient(accessTokenServer)
 )
 
-// Handle the request.
+// Handle the request. A route whose url ends with `/`, except the root, also
+// handles the paths under it, e.g. `/badge/` handles `/badge/year.svg`.
 func handle(w http.ResponseWriter, r *http.Request) {
 	for _, route := range Routes {
 		if route.url == r.URL.Path && route.method == r.Method {
@@ -34,6 +36,13 @@ func handle(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	for _, route := range Routes {
+		if route.url != "/" && strings.HasSuffix(route.url, "/") && strings.HasPrefix(r.URL.Path, route.url) && route.method == r.Method {
+			route.handle(w, r)
+			return
+		}
+	}
+
 	http.NotFound(w, r)
 }
 
@@ -46,6 +55,11 @@ func StartServer() {
 		return
 	}
 
+	if err := article.Load(Config.Article.Dir); err != nil {
+		fmt.Println("Invalid article assets:", err)
+		return
+	}
+
 	server := http.Server{
 		Addr:         ":" + port,
 		Handler:      http.HandlerFunc(handle),
@@ -75,7 +89,12 @@ func NewArticle(year int, progress float64) (*article.Article, error) {
 
 	log.Debugf("create article with progress value [%v]", p)
 
-	a, err := article.New(year, p)
+	a, err := article.NewWithOptions(year, p, article.Options{
+		Template:  article.DefaultTemplate,
+		Reference: article.Random,
+		Digest:    article.Random,
+		Locale:    Config.App.Locale,
+	})
 
 	if err != nil {
 		return nil, err
@@ -0,0 +1,224 @@
+package progressbar201X
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// bundleItem is an item of `Config.Bundle`.
+type bundleItem struct {
+	Kind         string
+	Title        string
+	Digest       string
+	Content      string
+	SourceURL    string
+	Cover        string
+	ShowCoverPic bool
+	Author       string
+}
+
+func bundleItems() []bundleItem {
+	if len(Config.Bundle) == 0 {
+		return []bundleItem{{Kind: "year"}}
+	}
+
+	items := make([]bundleItem, len(Config.Bundle))
+
+	for i, item := range Config.Bundle {
+		items[i] = bundleItem(item)
+	}
+
+	return items
+}
+
+// NewBundle composes the articles of the news message in the order of `Config.Bundle`,
+// headline is used as the year progress article. Items that are not available,
+// e.g. there is no history of this day last year, are skipped.
+func NewBundle(now time.Time, headline *article.Article) ([]*wechat.ArticleMaterial, error) {
+	items := bundleItems()
+
+	if len(items) > wechat.MaxNewsArticles {
+		return nil, fmt.Errorf("a bundle has at most %d articles, got %d", wechat.MaxNewsArticles, len(items))
+	}
+
+	var materials []*wechat.ArticleMaterial
+
+	for _, item := range items {
+		material, err := newBundleMaterial(now, headline, item)
+
+		if err != nil {
+			return nil, fmt.Errorf("compose %s article: %v", item.Kind, err)
+		}
+
+		if material == nil {
+			log.Infof("skip %s article", item.Kind)
+			continue
+		}
+
+		if material.ThumbMediaId == "" {
+			cover, err := wechat.GetRandomImageMaterial(wechatClient)
+
+			if err != nil {
+				return nil, err
+			}
+
+			material.ThumbMediaId = cover.MediaId
+		}
+
+		materials = append(materials, material)
+	}
+
+	if len(materials) == 0 {
+		return nil, errors.New("no article in the bundle")
+	}
+
+	return materials, nil
+}
+
+func newBundleMaterial(now time.Time, headline *article.Article, item bundleItem) (*wechat.ArticleMaterial, error) {
+	var a *article.Article
+	var err error
+
+	switch item.Kind {
+	case "year":
+		a = headline
+	case "quarter", "month", "week":
+		a, err = newPeriodArticle(now, item.Kind)
+	case "lastyear":
+		a, err = newLastYearArticle(now)
+	case "link":
+		a, err = newLinkArticle(item)
+	default:
+		err = fmt.Errorf("unknown kind %q", item.Kind)
+	}
+
+	if err != nil || a == nil {
+		return nil, err
+	}
+
+	material := &wechat.ArticleMaterial{
+		ThumbMediaId:     item.Cover,
+		Title:            a.Title,
+		Author:           item.Author,
+		Content:          a.Content,
+		ContentSourceURL: item.SourceURL,
+		Digest:           a.Digest,
+	}
+
+	if item.ShowCoverPic {
+		material.ShowCoverPic = 1
+	}
+
+	return material, nil
+}
+
+// newPeriodArticle creates the article of the quarter, month or week of now.
+func newPeriodArticle(now time.Time, kind string) (*article.Article, error) {
+	var progress float64
+	var err error
+	var name string
+
+	locale := Config.App.Locale
+
+	switch kind {
+	case "quarter":
+		progress, err = timeline.NewWithQuarter(now)
+		name = i18n.T(locale, "quarter.name", now.Year(), (int(now.Month())+2)/3)
+	case "month":
+		progress, err = timeline.NewWithMonth(now)
+		name = i18n.T(locale, "month.name", now.Year(), int(now.Month()), now.Month().String())
+	case "week":
+		progress, err = timeline.NewWithWeek(now)
+		name = i18n.T(locale, "week.name")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := math.Floor(progress * 100)
+	percent := i18n.FormatPercent(locale, p)
+
+	pageTitle := i18n.T(locale, "period.title", name, article.GenerateBar(p), percent)
+	contentTitle := i18n.T(locale, "period.heading", name, percent)
+
+	return article.NewWithTitles(pageTitle, contentTitle, now.Year(), p, article.Options{
+		Template:  article.DefaultTemplate,
+		Reference: article.Random,
+		Digest:    article.Random,
+		Locale:    locale,
+	})
+}
+
+// newLastYearArticle creates the article from the history of this day last year.
+func newLastYearArticle(now time.Time) (*article.Article, error) {
+	h, ok, err := store.Default().HistoryOf(store.DateKey(now.AddDate(-1, 0, 0)))
+
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	return &article.Article{
+		Title:   i18n.T(Config.App.Locale, "lastyear.title", h.Title),
+		Digest:  h.Digest,
+		Content: h.Content,
+	}, nil
+}
+
+// newLinkArticle creates the article of a curated link.
+func newLinkArticle(item bundleItem) (*article.Article, error) {
+	if item.Title == "" {
+		return nil, errors.New("title is required")
+	}
+
+	content := item.Content
+
+	if content == "" {
+		content = "<p>" + html.EscapeString(item.Digest) + "</p>"
+	}
+
+	return &article.Article{
+		Title:   item.Title,
+		Digest:  item.Digest,
+		Content: content,
+	}, nil
+}
+
+// UploadBundle uploads the articles as a news message to WeChat's server, ready to publish it.
+func UploadBundle(materials []*wechat.ArticleMaterial) (mediaId string, err error) {
+	for _, m := range materials {
+		log.WithFields(log.Fields{
+			"thumb_media_id": m.ThumbMediaId,
+			"title":          m.Title,
+			"digest":         m.Digest,
+		}).Info("create new article")
+	}
+
+	return wechat.UploadNewsMaterial(wechatClient, materials)
+}
+
+// RecordHistory records the broadcast article of now.
+func RecordHistory(now time.Time, a *article.Article, mediaId string) error {
+	return store.Default().AddHistory(store.History{
+		Date:        store.DateKey(now),
+		Year:        a.Data.Year,
+		Progress:    a.Data.Progress,
+		Title:       a.Title,
+		Digest:      a.Digest,
+		Content:     a.Content,
+		MediaId:     mediaId,
+		PublishedAt: time.Now(),
+	})
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sqrthree/progressbar201X"
+	"github.com/sqrthree/progressbar201X/internal/article"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+)
+
+// runCommand runs the subcommand of args, e.g. `menu sync`. The article assets of
+// `Config.Article.Dir` are loaded first, as the server does, so that posts are the
+// same as the broadcast ones.
+func runCommand(args []string) error {
+	if err := article.Load(Config.Article.Dir); err != nil {
+		return fmt.Errorf("invalid article assets: %v", err)
+	}
+
+	switch args[0] {
+	case "menu":
+		return menuCommand(args[1:])
+	case "followers":
+		return followersCommand(args[1:])
+	case "tags":
+		return tagsCommand(args[1:])
+	case "preview":
+		return previewCommand(args[1:])
+	case "telegram":
+		return telegramCommand(args[1:])
+	case "deliveries":
+		return deliveriesCommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
+func menuCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: progressbar201X menu sync|show")
+	}
+
+	switch args[0] {
+	case "sync":
+		flags := flag.NewFlagSet("menu sync", flag.ExitOnError)
+		file := flags.String("f", "menu.yml", "the menu definition")
+		dryRun := flags.Bool("dry-run", false, "validate the definition only")
+
+		flags.Parse(args[1:])
+
+		def, err := progressbar201X.LoadMenuDefinition(*file)
+
+		if err != nil {
+			return err
+		}
+
+		if *dryRun {
+			fmt.Println("The menu definition is valid.")
+			return nil
+		}
+
+		if err := progressbar201X.SyncMenu(def); err != nil {
+			return err
+		}
+
+		fmt.Println("The menu has been synced.")
+		return nil
+	case "show":
+		menu, conditional, err := progressbar201X.GetMenu()
+
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(map[string]interface{}{
+			"menu":        menu,
+			"conditional": conditional,
+		})
+	default:
+		return fmt.Errorf("unknown menu command %q", args[0])
+	}
+}
+
+func followersCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: progressbar201X followers list|export|tag|untag")
+	}
+
+	flags := flag.NewFlagSet("followers "+args[0], flag.ExitOnError)
+	lang := flags.String("lang", "zh_CN", "the language of the information, zh_CN, zh_TW or en")
+	output := flags.String("o", "", "the CSV file to export to, the standard output if it's empty")
+	tagId := flags.Int("tag", 0, "the id of the tag")
+
+	flags.Parse(args[1:])
+
+	switch args[0] {
+	case "list", "export":
+		followers, err := progressbar201X.Followers(*lang)
+
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "export" {
+			w := os.Stdout
+
+			if *output != "" {
+				f, err := os.Create(*output)
+
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+
+				w = f
+			}
+
+			return progressbar201X.WriteFollowersCSV(w, followers)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "OPENID\tSUBSCRIBED AT\tTAGS\tREMARK")
+
+		for _, f := range followers {
+			subscribedAt := time.Unix(f.SubscribeTime, 0).Format("2006-01-02 15:04")
+			fmt.Fprintf(w, "%s\t%s\t%v\t%s\n", f.OpenId, subscribedAt, f.TagIdList, f.Remark)
+		}
+
+		fmt.Fprintf(w, "%d followers\n", len(followers))
+
+		return w.Flush()
+	case "tag", "untag":
+		if *tagId == 0 || flags.NArg() == 0 {
+			return fmt.Errorf("usage: progressbar201X followers %s -tag ID OPENID...", args[0])
+		}
+
+		if args[0] == "untag" {
+			return progressbar201X.UntagFollowers(*tagId, flags.Args())
+		}
+
+		return progressbar201X.TagFollowers(*tagId, flags.Args())
+	default:
+		return fmt.Errorf("unknown followers command %q", args[0])
+	}
+}
+
+func tagsCommand(args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: progressbar201X tags list|create|rename|delete")
+	}
+
+	switch args[0] {
+	case "list":
+		tags, err := progressbar201X.Tags()
+
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tFOLLOWERS")
+
+		for _, tag := range tags {
+			fmt.Fprintf(w, "%d\t%s\t%d\n", tag.Id, tag.Name, tag.Count)
+		}
+
+		return w.Flush()
+	case "create":
+		if len(args) != 2 {
+			return errors.New("usage: progressbar201X tags create NAME")
+		}
+
+		tag, err := progressbar201X.CreateTag(args[1])
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tag %s has been created with id %d.\n", tag.Name, tag.Id)
+		return nil
+	case "rename", "delete":
+		if (args[0] == "rename" && len(args) != 3) || (args[0] == "delete" && len(args) != 2) {
+			return errors.New("usage: progressbar201X tags rename ID NAME | tags delete ID")
+		}
+
+		id, err := strconv.Atoi(args[1])
+
+		if err != nil {
+			return err
+		}
+
+		if args[0] == "delete" {
+			return progressbar201X.DeleteTag(id)
+		}
+
+		return progressbar201X.RenameTag(id, args[2])
+	default:
+		return fmt.Errorf("unknown tags command %q", args[0])
+	}
+}
+
+func previewCommand(args []string) error {
+	flags := flag.NewFlagSet("preview", flag.ExitOnError)
+	to := flags.String("to", "", "the OpenID of the recipient")
+	publisher := flags.String("publisher", progressbar201X.PublisherWechat, "the name of the publisher")
+
+	flags.Parse(args)
+
+	if *to == "" {
+		return errors.New("usage: progressbar201X preview -to OPENID [-publisher NAME]")
+	}
+
+	post, err := progressbar201X.NewPost(time.Now().UTC().Add(8 * time.Hour))
+
+	if err != nil {
+		return err
+	}
+
+	if err := progressbar201X.PreviewPost(*publisher, post, *to); err != nil {
+		return err
+	}
+
+	fmt.Printf("The post has been sent to %s.\n", *to)
+	return nil
+}
+
+func telegramCommand(args []string) error {
+	if len(args) == 0 || args[0] != "webhook" {
+		return errors.New("usage: progressbar201X telegram webhook")
+	}
+
+	if err := progressbar201X.SetTelegramWebhook(); err != nil {
+		return err
+	}
+
+	fmt.Println("The webhook of the bot has been set.")
+	return nil
+}
+
+func deliveriesCommand(args []string) error {
+	flags := flag.NewFlagSet("deliveries", flag.ExitOnError)
+	date := flags.String("date", time.Now().UTC().Add(8*time.Hour).Format("2006-01-02"), "the date of deliveries")
+	channel := flags.String("channel", "", "the channel of deliveries, e.g. template, telegram or webhook")
+
+	flags.Parse(args)
+
+	deliveries, err := progressbar201X.Deliveries(*date, *channel)
+
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHANNEL\tRECIPIENT\tSTATUS\tATTEMPTS\tAT\tERROR")
+
+	for _, d := range deliveries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", d.Channel, d.Recipient, d.Status, d.Attempts, d.At.Format("15:04:05"), d.Error)
+	}
+
+	fmt.Fprintf(w, "%d deliveries\n", len(deliveries))
+
+	return w.Flush()
+}
@@ -11,42 +11,55 @@ import (
 
 	"github.com/sqrthree/progressbar201X"
 	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/store"
 )
 
 func broadcast() {
-	progress, err := progressbar201X.GetProgressOfCurrentYear()
+	now := time.Now().UTC().Add(8 * time.Hour)
+
+	post, err := progressbar201X.NewPost(now)
 
 	if err != nil {
-		log.WithError(err).Error("get progress of this year")
+		log.WithError(err).Error("create post")
 		return
 	}
 
-	year := time.Now().Year()
-
-	artile, err := progressbar201X.NewArticle(year, progress)
+	pipeline, err := progressbar201X.NewPipeline()
 
 	if err != nil {
-		log.WithError(err).Error("create article")
+		log.WithError(err).Error("create pipeline")
 		return
 	}
 
-	mediaId, err := progressbar201X.UploadArticle(artile)
+	published := false
+	mediaId := ""
 
-	if err != nil {
-		log.WithError(err).Error("upload article")
-		return
-	}
+	for _, result := range pipeline.Run(post) {
+		log.WithFields(log.Fields{
+			"publisher": result.Publisher,
+			"status":    result.Status,
+			"id":        result.Id,
+			"attempts":  result.Attempts,
+		}).Info("publish post")
 
-	log.Info("upload article successfully, the article's mediaId is " + mediaId)
+		if result.Status != store.PublicationPublished {
+			continue
+		}
 
-	err = progressbar201X.BetchPostArticle(mediaId)
+		published = true
 
-	if err != nil {
-		log.WithError(err).Error("send article")
+		if result.Publisher == progressbar201X.PublisherWechat {
+			mediaId = result.Ref
+		}
+	}
+
+	if !published {
 		return
 	}
 
-	log.Infof("Article %s has been sent.\n", mediaId)
+	if err = progressbar201X.RecordHistory(now, post.Article, mediaId); err != nil {
+		log.WithError(err).Error("record history")
+	}
 }
 
 func main() {
@@ -61,6 +74,14 @@ func main() {
 
 	log.SetLevel(logLevel)
 
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1:]); err != nil {
+			log.WithError(err).Fatal(os.Args[1])
+		}
+
+		return
+	}
+
 	c := cron.New()
 
 	log.Info("timed task: 0 41 1 * * *")
@@ -72,6 +93,9 @@ func main() {
 		broadcast()
 	})
 
+	// Reminders of followers are checked every minute.
+	c.AddFunc("0 * * * * *", progressbar201X.DeliverReminders)
+
 	c.Start()
 
 	progressbar201X.StartServer()
@@ -0,0 +1,38 @@
+package progressbar201X
+
+import (
+	"errors"
+
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// ErrSessionClosed is returned if a customer service message is sent to a follower
+// who hasn't interacted with the account within `wechat.CustomMessageWindow`.
+var ErrSessionClosed = errors.New("the follower hasn't interacted with the account within 48 hours")
+
+// SessionOpen reports whether customer service messages can be sent to the follower.
+func SessionOpen(openId string) (bool, error) {
+	return store.Default().InteractedWithin(openId, wechat.CustomMessageWindow)
+}
+
+// SendCustomMessage sends a customer service message to the follower,
+// ErrSessionClosed is returned without sending if the window is closed.
+func SendCustomMessage(openId string, message wechat.CustomMessage) error {
+	open, err := SessionOpen(openId)
+
+	if err != nil {
+		return err
+	}
+
+	if !open {
+		return ErrSessionClosed
+	}
+
+	return wechat.SendCustomMessage(wechatClient, openId, message)
+}
+
+// SetTyping shows or hides the typing status to the follower.
+func SetTyping(openId string, typing bool) error {
+	return wechat.SetTyping(wechatClient, openId, typing)
+}
@@ -0,0 +1,136 @@
+package progressbar201X
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// Followers returns the information of all followers in lang.
+func Followers(lang string) ([]wechat.FollowerInfo, error) {
+	openIds, err := wechat.GetAllFollowers(wechatClient)
+
+	if err != nil {
+		return nil, err
+	}
+
+	followers := make([]wechat.FollowerInfo, 0, len(openIds))
+
+	for _, chunk := range chunkOpenIds(openIds, wechat.MaxBatchGetFollowers) {
+		infos, err := wechat.BatchGetFollowerInfo(wechatClient, chunk, lang)
+
+		if err != nil {
+			return nil, err
+		}
+
+		followers = append(followers, infos...)
+	}
+
+	return followers, nil
+}
+
+// Tags returns all tags of the account.
+func Tags() ([]wechat.Tag, error) {
+	return wechat.GetAllTags(wechatClient)
+}
+
+// CreateTag creates a tag of name.
+func CreateTag(name string) (wechat.Tag, error) {
+	return wechat.CreateTag(wechatClient, name)
+}
+
+// RenameTag renames the tag of id to name.
+func RenameTag(id int, name string) error {
+	return wechat.UpdateTag(wechatClient, id, name)
+}
+
+// DeleteTag deletes the tag of id.
+func DeleteTag(id int) error {
+	return wechat.DeleteTag(wechatClient, id)
+}
+
+// TagFollowers tags the followers with the tag of id, e.g. to receive broadcasts.
+func TagFollowers(id int, openIds []string) error {
+	for _, chunk := range chunkOpenIds(openIds, wechat.MaxBatchTagging) {
+		if err := wechat.BatchTagging(wechatClient, id, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UntagFollowers removes the tag of id from the followers.
+func UntagFollowers(id int, openIds []string) error {
+	for _, chunk := range chunkOpenIds(openIds, wechat.MaxBatchTagging) {
+		if err := wechat.BatchUntagging(wechatClient, id, chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteFollowersCSV writes followers to w as CSV with a header.
+func WriteFollowersCSV(w io.Writer, followers []wechat.FollowerInfo) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"openid", "subscribed", "subscribe_time", "language", "remark", "tags", "subscribe_scene", "unionid"}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, f := range followers {
+		tags := make([]string, len(f.TagIdList))
+
+		for i, id := range f.TagIdList {
+			tags[i] = strconv.Itoa(id)
+		}
+
+		var subscribeTime string
+
+		if f.SubscribeTime > 0 {
+			subscribeTime = time.Unix(f.SubscribeTime, 0).UTC().Format(time.RFC3339)
+		}
+
+		record := []string{
+			f.OpenId,
+			strconv.FormatBool(f.Subscribe == 1),
+			subscribeTime,
+			f.Language,
+			f.Remark,
+			strings.Join(tags, ";"),
+			f.SubscribeScene,
+			f.UnionId,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
+
+// chunkOpenIds splits openIds into chunks of up to size.
+func chunkOpenIds(openIds []string, size int) [][]string {
+	var chunks [][]string
+
+	for len(openIds) > size {
+		chunks = append(chunks, openIds[:size])
+		openIds = openIds[size:]
+	}
+
+	if len(openIds) > 0 {
+		chunks = append(chunks, openIds)
+	}
+
+	return chunks
+}
@@ -2,18 +2,16 @@
 package article
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"math/rand"
 	"net/http"
-	"path/filepath"
 	"regexp"
-	"text/template"
 	"time"
 
 	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/i18n"
 )
 
 const articleOptionsURL = "https://raw.githubusercontent.com/sqrthree/progressbar201X/quotations/main.json"
@@ -22,6 +20,14 @@ type Article struct {
 	Title   string
 	Digest  string
 	Content string
+	// Data is the data the content is rendered from.
+	Data Data
+}
+
+// Render renders the content of the article with r,
+// the result shares the same data and reference as Content.
+func (a *Article) Render(r Renderer) (string, error) {
+	return r.Render(a.Data)
 }
 
 type ReferenceOption struct {
@@ -35,72 +41,50 @@ type CustomizedOptions struct {
 	References []ReferenceOption
 }
 
-// getArticleTemplate reads the content of `PROJECT_ROOT/article_template.html`
-// and use it as the template of article.
-func getArticleTemplate() (*template.Template, error) {
-	file, err := filepath.Abs("./article_template.html")
-
-	log.Debug("read article template from " + file)
-
-	if err != nil {
-		return nil, err
-	}
-
-	conts, err := ioutil.ReadFile(file)
-
-	if err != nil {
-		return nil, err
-	}
-
-	temp, err := template.New("article").Parse(compressHTMLString(string(conts)))
-
-	if err != nil {
-		return nil, err
-	}
-
-	return temp, nil
-}
-
 // compressHTMLString compresses HTML code and retrun the compressed string.
 func compressHTMLString(s string) string {
 	return regexp.MustCompile("\\s*(<[^><]*>)\\s*").ReplaceAllString(s, "$1")
 }
 
-// renderArticle renders a article template with random options from `articleOptionsURL`.
-func renderArticle(contentTitle string, reference ReferenceOption) (string, error) {
-	t, err := getArticleTemplate()
+// getCustomizedOptions returns the options overridden in the assets directory,
+// otherwise fetches latest options from `articleOptionsURL`, falling back to
+// the built-in options if they can't be fetched.
+func getCustomizedOptions() (CustomizedOptions, error) {
+	assetsMutex.Lock()
+	dir := overrideDir
+	assetsMutex.Unlock()
 
-	if err != nil {
-		return "", err
+	if hasLocalOptions(dir) {
+		return readLocalOptions(dir)
 	}
 
-	var buf bytes.Buffer
+	options, err := fetchCustomizedOptions()
 
-	type ContentOption struct {
-		Title   string
-		Content ReferenceOption
+	if err == nil {
+		err = options.validate()
 	}
 
-	err = t.Execute(&buf, ContentOption{contentTitle, reference})
-
 	if err != nil {
-		return "", err
+		log.WithError(err).Warn("fetch customized options, use built-in options instead")
+
+		return readLocalOptions("")
 	}
 
-	return buf.String(), nil
+	return options, nil
 }
 
-// getCustomizedOptions fetches latest options from `articleOptionsURL`
-func getCustomizedOptions() (CustomizedOptions, error) {
+// fetchCustomizedOptions fetches latest options from `articleOptionsURL`
+func fetchCustomizedOptions() (CustomizedOptions, error) {
 	var options CustomizedOptions
 
 	res, err := http.Get(articleOptionsURL)
-	defer res.Body.Close()
 
 	if err != nil {
 		return options, err
 	}
 
+	defer res.Body.Close()
+
 	if res.StatusCode != http.StatusOK {
 		err = fmt.Errorf("fetch article options, got http.Status: %s", res.Status)
 		return options, err
@@ -130,11 +114,41 @@ func GenerateBar(p float64) string {
 	return str
 }
 
+// Random selects a random item when it's used as an index of Options.
+const Random = -1
+
+// Options specifies the template, reference and digest of a new article.
+type Options struct {
+	Template  string
+	Reference int
+	Digest    int
+	// Locale is the locale of titles and templates, defaults to `i18n.Default`.
+	Locale string
+}
+
 // New creates a new article with specified value
 func New(year int, p float64) (*Article, error) {
+	return NewWithOptions(year, p, Options{
+		Template:  DefaultTemplate,
+		Reference: Random,
+		Digest:    Random,
+	})
+}
+
+// NewWithOptions creates a new article with specified value and options.
+func NewWithOptions(year int, p float64, o Options) (*Article, error) {
+	bar := GenerateBar(p)
+	percent := i18n.FormatPercent(o.Locale, p)
+	pageTitle := i18n.T(o.Locale, "year.title", year, bar, percent)
+	contentTitle := i18n.T(o.Locale, "year.heading", year, percent)
+
+	return NewWithTitles(pageTitle, contentTitle, year, p, o)
+}
+
+// NewWithTitles creates a new article with specified titles, it's used by
+// articles of other periods than a year.
+func NewWithTitles(pageTitle, contentTitle string, year int, p float64, o Options) (*Article, error) {
 	bar := GenerateBar(p)
-	pageTitle := fmt.Sprintf("%v 年已经走过了 %s %v%s", year, bar, p, "%")
-	contentTitle := fmt.Sprintf("%v 年已经走过了 %v%s 啦", year, p, "%")
 
 	options, err := getCustomizedOptions()
 
@@ -146,10 +160,28 @@ func New(year int, p float64) (*Article, error) {
 
 	r := rand.New(rand.NewSource(time.Now().UnixNano()))
 
-	referenceIndex := r.Intn(len(options.References))
-	digestIndex := r.Intn(len(options.Digests))
+	referenceIndex, err := pickIndex(r, o.Reference, len(options.References))
+
+	if err != nil {
+		return nil, fmt.Errorf("reference %v", err)
+	}
+
+	digestIndex, err := pickIndex(r, o.Digest, len(options.Digests))
 
-	articleContent, err := renderArticle(contentTitle, options.References[referenceIndex])
+	if err != nil {
+		return nil, fmt.Errorf("digest %v", err)
+	}
+
+	data := Data{
+		Year:     year,
+		Progress: p,
+		Bar:      bar,
+		Title:    contentTitle,
+		Content:  options.References[referenceIndex],
+		Locale:   i18n.Match(o.Locale),
+	}
+
+	articleContent, err := HTMLRenderer{Template: o.Template}.Render(data)
 
 	if err != nil {
 		log.WithError(err).Error("render article")
@@ -161,6 +193,7 @@ func New(year int, p float64) (*Article, error) {
 		Title:   pageTitle,
 		Digest:  options.Digests[digestIndex],
 		Content: articleContent,
+		Data:    data,
 	}
 
 	log.WithFields(log.Fields{
@@ -170,3 +203,21 @@ func New(year int, p float64) (*Article, error) {
 
 	return &article, nil
 }
+
+// pickIndex returns index if it's in [0, n), or a random one if index is Random.
+func pickIndex(r *rand.Rand, index, n int) (int, error) {
+	if index == Random {
+		return r.Intn(n), nil
+	}
+
+	if index < 0 || index >= n {
+		return 0, fmt.Errorf("index %d out of range [0, %d)", index, n)
+	}
+
+	return index, nil
+}
+
+// Quotations returns the references and digests used to create articles.
+func Quotations() (CustomizedOptions, error) {
+	return getCustomizedOptions()
+}
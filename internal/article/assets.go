@@ -0,0 +1,260 @@
+package article
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+)
+
+// DefaultTemplate is the name of the template used to render articles
+// when no other template is specified.
+const DefaultTemplate = "article"
+
+const (
+	templatesDir   = "templates"
+	quotationsFile = "quotations.json"
+)
+
+// defaultAssets holds the built-in templates and quotations, so the binary
+// works without any file next to it.
+//
+//go:embed assets
+var defaultAssets embed.FS
+
+var (
+	assetsMutex sync.Mutex
+	overrideDir string
+	templates   map[string]*template.Template
+)
+
+// Load parses and validates all article templates and quotations.
+//
+// The built-in assets are loaded first, then the files in `dir` (if any)
+// take precedence: `dir/templates/*.html` replaces or adds templates by
+// file name, and `dir/quotations.json` replaces the built-in quotations.
+//
+// A template can be localized by adding the locale to its name,
+// e.g. `templates/article.en.html` is used to render `article` in `en`.
+func Load(dir string) error {
+	assetsMutex.Lock()
+	defer assetsMutex.Unlock()
+
+	return load(dir)
+}
+
+func load(dir string) error {
+	builtin, err := fs.Sub(defaultAssets, "assets")
+
+	if err != nil {
+		return err
+	}
+
+	sources := []fs.FS{builtin}
+
+	if dir != "" {
+		log.Debug("read article assets from " + dir)
+
+		sources = append(sources, os.DirFS(dir))
+	}
+
+	loaded := make(map[string]*template.Template)
+
+	for _, source := range sources {
+		if err := parseTemplates(source, loaded); err != nil {
+			return err
+		}
+	}
+
+	for name, t := range loaded {
+		if err := validateTemplate(t); err != nil {
+			return fmt.Errorf("validate template %q: %v", name, err)
+		}
+	}
+
+	options, err := readLocalOptions(dir)
+
+	if err != nil {
+		return err
+	}
+
+	if err := options.validate(); err != nil {
+		return fmt.Errorf("validate quotations: %v", err)
+	}
+
+	overrideDir = dir
+	templates = loaded
+
+	log.WithField("templates", strings.Join(templateNames(loaded), ",")).Debug("article assets loaded")
+
+	return nil
+}
+
+// parseTemplates parses every `templates/*.html` file of source into loaded,
+// the template is named after the file name without extension.
+func parseTemplates(source fs.FS, loaded map[string]*template.Template) error {
+	files, err := fs.Glob(source, templatesDir+"/*.html")
+
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		conts, err := fs.ReadFile(source, file)
+
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(filepath.Base(file), ".html")
+
+		temp, err := template.New(name).Parse(compressHTMLString(string(conts)))
+
+		if err != nil {
+			return err
+		}
+
+		loaded[name] = temp
+	}
+
+	return nil
+}
+
+// validateTemplate renders t with sample data, so references to unknown
+// fields are reported before the template is used for real.
+func validateTemplate(t *template.Template) error {
+	sample := Data{
+		Year:     2018,
+		Progress: 50,
+		Bar:      GenerateBar(50),
+		Title:    "2018 年已经走过了 50% 啦",
+		Content: ReferenceOption{
+			Body:      "body",
+			Author:    "author",
+			Reference: "reference",
+		},
+		Locale: i18n.Default,
+	}
+
+	return t.Execute(ioutil.Discard, sample)
+}
+
+func templateNames(m map[string]*template.Template) []string {
+	names := make([]string, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Templates returns the names of all available templates.
+func Templates() ([]string, error) {
+	assetsMutex.Lock()
+	defer assetsMutex.Unlock()
+
+	if templates == nil {
+		if err := load(overrideDir); err != nil {
+			return nil, err
+		}
+	}
+
+	return templateNames(templates), nil
+}
+
+// getArticleTemplate returns the template with the specified name,
+// the localized one of locale is preferred.
+func getArticleTemplate(name, locale string) (*template.Template, error) {
+	assetsMutex.Lock()
+	defer assetsMutex.Unlock()
+
+	if templates == nil {
+		if err := load(overrideDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if locale != "" {
+		for _, l := range i18n.Fallbacks(locale) {
+			if t, ok := templates[name+"."+l]; ok {
+				return t, nil
+			}
+		}
+	}
+
+	t, ok := templates[name]
+
+	if !ok {
+		return nil, fmt.Errorf("template %q not found", name)
+	}
+
+	return t, nil
+}
+
+// readLocalOptions reads quotations from `dir/quotations.json`,
+// or from the built-in assets if the file doesn't exist.
+func readLocalOptions(dir string) (CustomizedOptions, error) {
+	var options CustomizedOptions
+
+	if dir != "" {
+		conts, err := ioutil.ReadFile(filepath.Join(dir, quotationsFile))
+
+		if err == nil {
+			err = json.Unmarshal(conts, &options)
+
+			return options, err
+		}
+
+		if !os.IsNotExist(err) {
+			return options, err
+		}
+	}
+
+	conts, err := defaultAssets.ReadFile("assets/" + quotationsFile)
+
+	if err != nil {
+		return options, err
+	}
+
+	err = json.Unmarshal(conts, &options)
+
+	return options, err
+}
+
+// hasLocalOptions reports whether quotations are overridden in `dir`.
+func hasLocalOptions(dir string) bool {
+	if dir == "" {
+		return false
+	}
+
+	_, err := os.Stat(filepath.Join(dir, quotationsFile))
+
+	return err == nil
+}
+
+func (o CustomizedOptions) validate() error {
+	if len(o.Digests) == 0 {
+		return errors.New("no digests")
+	}
+
+	if len(o.References) == 0 {
+		return errors.New("no references")
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package article
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+)
+
+// Data is the data model shared by all renderers,
+// it's also the data passed to HTML templates.
+type Data struct {
+	Year     int
+	Progress float64
+	Bar      string
+	// Title is the title of the content, e.g. `2018 年已经走过了 50% 啦`.
+	Title string
+	// Content is the reference quoted in the article.
+	Content ReferenceOption
+	// Locale is the locale of the article, it selects the HTML template.
+	Locale string
+}
+
+// Renderer renders the content of an article in a specific format.
+type Renderer interface {
+	Render(d Data) (string, error)
+}
+
+// Formats of the built-in renderers.
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+	FormatText     = "text"
+)
+
+// NewRenderer returns the built-in renderer of format,
+// HTML is rendered with the default template.
+func NewRenderer(format string) (Renderer, error) {
+	switch format {
+	case FormatHTML:
+		return HTMLRenderer{Template: DefaultTemplate}, nil
+	case FormatMarkdown:
+		return MarkdownRenderer{}, nil
+	case FormatText:
+		return TextRenderer{}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+// HTMLRenderer renders compressed HTML with the named article template,
+// the template of the data's locale is preferred, see `Load`.
+type HTMLRenderer struct {
+	Template string
+}
+
+func (r HTMLRenderer) Render(d Data) (string, error) {
+	name := r.Template
+
+	if name == "" {
+		name = DefaultTemplate
+	}
+
+	t, err := getArticleTemplate(name, d.Locale)
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err := t.Execute(&buf, d); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// MarkdownRenderer renders CommonMark, the reference is rendered as a blockquote.
+type MarkdownRenderer struct{}
+
+func (MarkdownRenderer) Render(d Data) (string, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# %s\n\n", escapeMarkdown(d.Title))
+	fmt.Fprintf(&buf, "`%s` %s\n\n", d.Bar, i18n.FormatPercent(d.Locale, d.Progress))
+
+	if d.Content.Body != "" {
+		fmt.Fprintf(&buf, "> %s\n", escapeMarkdown(d.Content.Body))
+
+		if source := referenceSource(d.Content); source != "" {
+			fmt.Fprintf(&buf, ">\n> —— %s\n", escapeMarkdown(source))
+		}
+	}
+
+	return buf.String(), nil
+}
+
+var markdownReplacer = strings.NewReplacer(
+	"\\", "\\\\",
+	"`", "\\`",
+	"*", "\\*",
+	"_", "\\_",
+	"[", "\\[",
+	"]", "\\]",
+	"<", "\\<",
+	">", "\\>",
+	"#", "\\#",
+	"|", "\\|",
+	"\n", " ",
+)
+
+// escapeMarkdown escapes s to be used as inline text of Markdown.
+func escapeMarkdown(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// TextRenderer renders plain text.
+type TextRenderer struct{}
+
+func (TextRenderer) Render(d Data) (string, error) {
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "%s\n%s %s\n", d.Title, d.Bar, i18n.FormatPercent(d.Locale, d.Progress))
+
+	if d.Content.Body != "" {
+		fmt.Fprintf(&buf, "\n%s\n", d.Content.Body)
+
+		if source := referenceSource(d.Content); source != "" {
+			fmt.Fprintf(&buf, "—— %s\n", source)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// referenceSource returns the author and the reference joined with a space.
+func referenceSource(r ReferenceOption) string {
+	return strings.TrimSpace(r.Author + " " + r.Reference)
+}
@@ -0,0 +1,245 @@
+// Package badge draws badges of progress, e.g. `2026 | 79%`, as SVG or PNG.
+package badge
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/sqrthree/progressbar201X/internal/cover"
+)
+
+// Styles of badges.
+const (
+	// StyleFlat is a badge of rounded corners, the message is on Color.
+	StyleFlat = "flat"
+	// StyleFlatSquare is StyleFlat without rounded corners.
+	StyleFlatSquare = "flat-square"
+	// StyleProgress fills the message by Color as far as the progress.
+	StyleProgress = "progress"
+	// StyleText prints the text bar of the progress before the message.
+	StyleText = "text"
+)
+
+// Styles lists the supported styles.
+var Styles = []string{StyleFlat, StyleFlatSquare, StyleProgress, StyleText}
+
+// Default colors.
+var (
+	LabelColor = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	Color      = cover.Fill
+	trackColor = color.RGBA{0xe5, 0xe5, 0xe5, 0xff}
+	textColor  = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	darkColor  = cover.Text
+)
+
+// Badge is a badge of Label and Message.
+type Badge struct {
+	Label   string
+	Message string
+	// Progress is in [0, 1], it's drawn by StyleProgress.
+	Progress float64
+	// Bar is the text bar printed by StyleText, e.g. `▓▓▓▓▓▓▓░░░`.
+	Bar        string
+	Style      string
+	LabelColor color.RGBA
+	Color      color.RGBA
+}
+
+// ParseColor parses a hex color of 3 or 6 digits, the leading # is optional.
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.TrimPrefix(s, "#")
+
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+
+	if len(s) != 6 {
+		return color.RGBA{}, errors.New("invalid color " + s)
+	}
+
+	v, err := strconv.ParseUint(s, 16, 32)
+
+	if err != nil {
+		return color.RGBA{}, errors.New("invalid color " + s)
+	}
+
+	return color.RGBA{uint8(v >> 16), uint8(v >> 8), uint8(v), 0xff}, nil
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// textWidth estimates the width of s in 11px Verdana, wide characters, e.g. CJK, are
+// twice as wide as Latin ones.
+func textWidth(s string) int {
+	width := 0
+
+	for _, r := range s {
+		switch {
+		case r < 0x80:
+			width += 7
+		case r >= 0x2580 && r <= 0x259f:
+			// Block elements of text bars.
+			width += 8
+		default:
+			width += 12
+		}
+	}
+
+	return width
+}
+
+type svgData struct {
+	Badge
+	Text                       string
+	Width, LabelWidth          int
+	MessageX, MessageWidth     int
+	FillWidth                  int
+	LabelX                     int
+	Rounded, Gradient, Track   bool
+	LabelFill, Fill, TextColor string
+}
+
+var svgTemplate = template.Must(template.New("svg").Funcs(template.FuncMap{
+	"xml": template.HTMLEscapeString,
+}).Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{.Width}}" height="20" role="img" aria-label="{{xml .Label}}: {{xml .Text}}">
+<title>{{xml .Label}}: {{xml .Text}}</title>
+{{- if .Gradient}}
+<linearGradient id="s" x2="0" y2="100%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>
+{{- end}}
+<clipPath id="r"><rect width="{{.Width}}" height="20" rx="{{if .Rounded}}3{{else}}0{{end}}" fill="#fff"/></clipPath>
+<g clip-path="url(#r)">
+<rect width="{{.LabelWidth}}" height="20" fill="{{.LabelFill}}"/>
+{{- if .Track}}
+<rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="#e5e5e5"/>
+<rect x="{{.LabelWidth}}" width="{{.FillWidth}}" height="20" fill="{{.Fill}}"/>
+{{- else}}
+<rect x="{{.LabelWidth}}" width="{{.MessageWidth}}" height="20" fill="{{.Fill}}"/>
+{{- end}}
+{{- if .Gradient}}
+<rect width="{{.Width}}" height="20" fill="url(#s)"/>
+{{- end}}
+</g>
+<g text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+<text x="{{.LabelX}}" y="14" fill="#fff">{{xml .Label}}</text>
+<text x="{{.MessageX}}" y="14" fill="{{.TextColor}}">{{xml .Text}}</text>
+</g>
+</svg>
+`))
+
+// SVG returns the badge as SVG.
+func (b *Badge) SVG() ([]byte, error) {
+	d := svgData{
+		Badge:     *b,
+		Text:      b.Message,
+		Rounded:   b.Style != StyleFlatSquare,
+		Gradient:  b.Style != StyleFlatSquare,
+		Track:     b.Style == StyleProgress,
+		LabelFill: hex(b.LabelColor),
+		Fill:      hex(b.Color),
+		TextColor: "#fff",
+	}
+
+	if b.Style == StyleText {
+		d.Text = b.Bar + " " + b.Message
+	}
+
+	if d.Track {
+		d.TextColor = hex(darkColor)
+	}
+
+	d.LabelWidth = textWidth(b.Label) + 12
+	d.MessageWidth = textWidth(d.Text) + 12
+	d.Width = d.LabelWidth + d.MessageWidth
+	d.LabelX = d.LabelWidth / 2
+	d.MessageX = d.LabelWidth + d.MessageWidth/2
+	d.FillWidth = int(float64(d.MessageWidth) * clamp(b.Progress))
+
+	var buf bytes.Buffer
+
+	if err := svgTemplate.Execute(&buf, d); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// pngScale is the scale of the bitmap font of PNG badges.
+const pngScale = 2
+
+// WritePNG writes the badge as PNG to w. It's printed in the bitmap font of covers,
+// which only has digits and a few symbols, so the label is skipped unless it's
+// Printable, and StyleText is drawn as StyleProgress.
+func (b *Badge) WritePNG(w io.Writer) error {
+	return png.Encode(w, b.render())
+}
+
+// Printable reports whether s can be printed on PNG badges.
+func Printable(s string) bool {
+	return cover.Printable(s)
+}
+
+func (b *Badge) render() *image.RGBA {
+	padding := 4 * pngScale
+	height := cover.TextHeight(pngScale) + 2*padding
+
+	label := b.Label
+
+	if !cover.Printable(label) {
+		label = ""
+	}
+
+	labelWidth := cover.TextWidth(label, pngScale) + 2*padding
+	messageWidth := cover.TextWidth(b.Message, pngScale) + 2*padding
+
+	if label == "" {
+		labelWidth = 0
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, labelWidth+messageWidth, height))
+
+	fill(img, image.Rect(0, 0, labelWidth, height), b.LabelColor)
+
+	message := image.Rect(labelWidth, 0, labelWidth+messageWidth, height)
+	text := color.Color(textColor)
+
+	if b.Style == StyleProgress || b.Style == StyleText {
+		fill(img, message, trackColor)
+
+		message.Max.X = message.Min.X + int(float64(messageWidth)*clamp(b.Progress))
+		text = darkColor
+	}
+
+	fill(img, message, b.Color)
+
+	cover.PrintText(img, label, padding, padding, pngScale, textColor)
+	cover.PrintText(img, b.Message, labelWidth+padding, padding, pngScale, text)
+
+	return img
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+func clamp(p float64) float64 {
+	if p < 0 {
+		return 0
+	}
+
+	if p > 1 {
+		return 1
+	}
+
+	return p
+}
@@ -0,0 +1,81 @@
+// Package cache implements a cache in memory of bytes with expiration.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// LRU is a cache in memory holding up to capacity entries, the expired and the
+// least recently set ones are evicted first. It's safe for concurrent use.
+type LRU struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+}
+
+func New(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value of key, ok is false if key is not set or expired.
+func (c *LRU) Get(key string) (value []byte, ok bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	e, ok := c.entries[key]
+
+	if !ok {
+		return nil, false
+	}
+
+	en := e.Value.(*entry)
+
+	if time.Now().After(en.expires) {
+		c.order.Remove(e)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return en.value, true
+}
+
+// Set sets the value of key which expires after ttl.
+func (c *LRU) Set(key string, value []byte, ttl time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	en := &entry{key, value, time.Now().Add(ttl)}
+
+	if e, ok := c.entries[key]; ok {
+		e.Value = en
+		c.order.MoveToBack(e)
+	} else {
+		c.entries[key] = c.order.PushBack(en)
+	}
+
+	now := time.Now()
+
+	for e := c.order.Front(); e != nil; e = c.order.Front() {
+		oldest := e.Value.(*entry)
+
+		if c.order.Len() <= c.capacity && now.Before(oldest.expires) {
+			break
+		}
+
+		c.order.Remove(e)
+		delete(c.entries, oldest.key)
+	}
+}
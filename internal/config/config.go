@@ -9,15 +9,172 @@ import (
 var Config = struct {
 	App struct {
 		Debug bool
+		// Locale is the locale of the account, followers may set their own.
+		Locale string `default:"zh-CN"`
 	}
 	Server struct {
 		Port uint64
+		// BaseURL is the public URL of the server, e.g. `https://example.com`,
+		// it's used to link generated images in replies.
+		BaseURL string
+	}
+	Article struct {
+		// Dir overrides the built-in article templates and quotations.
+		Dir string
+	}
+	Store struct {
+		Path string `default:"store.json"`
+	}
+	// Feed configures the feeds of the broadcast history, e.g. `/feed.xml`.
+	Feed struct {
+		Title       string `default:"Progress Bar"`
+		Description string
+		// Limit is the maximum number of the latest entries.
+		Limit int `default:"30"`
+	}
+	// Bundle lists the articles of the broadcast news message in order,
+	// only the year progress article is sent if it's empty.
+	Bundle []struct {
+		// Kind is one of year, quarter, month, week, lastyear and link.
+		Kind string
+		// Title, Digest, Content and SourceURL are used by link articles.
+		Title     string
+		Digest    string
+		Content   string
+		SourceURL string
+		// Cover is the media id of the thumb, a random image material is used if it's empty.
+		Cover        string
+		ShowCoverPic bool
+		Author       string
+	}
+	// Publish configures the publishers of broadcasts.
+	Publish struct {
+		// Publishers are the names of publishers, only wechat is used if it's empty.
+		Publishers []string
+		// Attempts is the maximum number of attempts of each publisher.
+		Attempts int `default:"3"`
+		// Backoff in seconds before the first retry, it's doubled for each retry.
+		Backoff int `default:"30"`
+	}
+	Preview struct {
+		// The preview endpoint is disabled unless a password is set.
+		Username string
+		Password string
 	}
 	Wechat struct {
 		AppId     string
 		AppSecret string
 		Token     string
 		AESKey    string
+		// EncryptMode is one of plaintext, compatible and safe, the same as the console.
+		EncryptMode string `default:"safe"`
+		// FallbackReply is replied to unsupported messages, nothing is replied if it's empty.
+		FallbackReply string
+		// Welcome is replied to new followers with the progress of the year, month and week.
+		Welcome struct {
+			// Type is text or news, a news card needs `Server.BaseURL` for the cover.
+			Type string `default:"text"`
+			// Greeting precedes the progress, a localized one is used if it's empty.
+			Greeting string
+			// URL is the link of the news card, it's the cover if empty.
+			URL string
+		}
+		// Template configures the template messages of broadcasts.
+		Template struct {
+			// Mode is mass to broadcast by mass send only, fallback to send template
+			// messages if the mass send fails, or template to send template messages only.
+			Mode       string `default:"mass"`
+			TemplateId string
+			// TagId is the tag of the recipients, the same as the mass send.
+			TagId int `default:"2"`
+			// AllFollowers sends to all followers instead of the followers of TagId.
+			AllFollowers bool
+			// URL and Fields are text/template strings of the fields of
+			// `progressbar201X.TemplateContext`, e.g. `{{.Title}}`. Fields are keyed
+			// by the keys of the template, e.g. first, keyword1 and remark.
+			URL    string
+			Fields map[string]string
+			// Colors are the colors of fields, e.g. `#173177`.
+			Colors map[string]string
+		}
+		// Dedupe caches replies to replay them to the retries of WeChat.
+		Dedupe struct {
+			// TTL in seconds, WeChat retries 3 times in 15 seconds.
+			TTL int `default:"60"`
+			// Capacity is the maximum number of replies cached in memory.
+			Capacity int `default:"10000"`
+			// Store is memory, or store to cache replies in the store.
+			Store string `default:"memory"`
+		}
+		// Replay rejects callbacks with stale timestamps or reused nonces, only the
+		// retries of WeChat within the TTL of Dedupe may reuse a nonce.
+		Replay struct {
+			// Window in seconds of the skew of timestamps, 0 disables the check.
+			Window int `default:"300"`
+			// Capacity is the maximum number of nonces remembered.
+			Capacity int `default:"100000"`
+		}
+	}
+	// Webhook configures the endpoints the webhook publisher posts the progress to.
+	Webhook struct {
+		// Attempts and Backoff in seconds of each endpoint, the backoff is doubled for each retry.
+		Attempts int `default:"3"`
+		Backoff  int `default:"5"`
+		// Timeout of each request in seconds.
+		Timeout int `default:"10"`
+		// URL is the text/template of the URL of the article in the payload, the
+		// same as `Wechat.Template.URL`.
+		URL       string
+		Endpoints []struct {
+			Name string
+			URL  string
+			// Body is the text/template of the body for vendor formats, the fields are
+			// the ones of `progressbar201X.WebhookPayload`, and `json` quotes a value,
+			// e.g. `{"text": {{json .Title}}}`. The payload is posted if it's empty.
+			Body        string
+			ContentType string
+			// Secret signs the requests by HMAC-SHA256, see the webhook package.
+			Secret  string
+			Headers map[string]string
+		}
+	}
+	// Mail configures the newsletter publisher and the route `/unsubscribe`.
+	Mail struct {
+		Host string
+		Port int `default:"587"`
+		// Username and Password authenticate after STARTTLS if Username is set.
+		Username string
+		Password string
+		// NoTLS disables STARTTLS, it's only meant for local servers, e.g. a stub in tests.
+		NoTLS bool
+		// From is the sender, e.g. `Progress <progress@example.com>`.
+		From string
+		// Subscribers is the file of addresses, one per line.
+		Subscribers string `default:"subscribers.txt"`
+		// Rate is the maximum number of mails sent per minute, 0 is unlimited.
+		Rate int `default:"60"`
+		// Timeout of each SMTP command in seconds.
+		Timeout int `default:"30"`
+		// Secret signs the links to unsubscribe, which are relative to `Server.BaseURL`.
+		Secret string
+	}
+	// Telegram configures the bot of Telegram, the publisher and the webhook `/telegram`.
+	Telegram struct {
+		Token string
+		// Username of the bot, commands addressed to other bots are ignored. It's
+		// fetched by getMe if it's empty.
+		Username string
+		// BaseURL of the Bot API, it may be a local stub in tests.
+		BaseURL string `default:"https://api.telegram.org"`
+		// Channels are the chat ids or @usernames of the channels to publish to.
+		Channels []string
+		// Photo publishes the cover with the caption instead of the text.
+		Photo bool
+		// Secret is the secret token of the webhook, updates without it are rejected.
+		// The webhook is disabled unless it's set.
+		Secret string
+		// Timeout in seconds of requests to the Bot API.
+		Timeout int `default:"10"`
 	}
 }{}
 
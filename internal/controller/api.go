@@ -0,0 +1,179 @@
+package controller
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	// The time zones are embedded, the server may run without tzdata, e.g. in a scratch image.
+	_ "time/tzdata"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	"github.com/sqrthree/progressbar201X/internal/query"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+)
+
+// defaultTimezone is the time zone of the API if it's not specified, the same as the account.
+const defaultTimezone = "Asia/Shanghai"
+
+// openAPISpec describes the API, it's served by `OpenAPI`.
+//
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// progressResponse is the response of `Progress`.
+type progressResponse struct {
+	Period   string    `json:"period"`
+	Timezone string    `json:"timezone"`
+	At       time.Time `json:"at"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	// Ratio is in [0, 1] with 4 decimals, and Percent is in [0, 100] with 2 decimals.
+	Ratio   float64 `json:"ratio"`
+	Percent float64 `json:"percent"`
+	// Elapsed and Remaining are in seconds.
+	Elapsed   int64  `json:"elapsed"`
+	Remaining int64  `json:"remaining"`
+	Bar       string `json:"bar"`
+}
+
+type apiError struct {
+	Error string `json:"error"`
+}
+
+var apiPeriods = map[string]bool{
+	query.PeriodYear:    true,
+	query.PeriodQuarter: true,
+	query.PeriodMonth:   true,
+	query.PeriodWeek:    true,
+	query.PeriodDay:     true,
+}
+
+// Progress returns the progress of a period in JSON, see openapi.yaml.
+//
+// Query parameters:
+//   - period: year, quarter, month, week or day, defaults to year.
+//   - tz: the IANA time zone, defaults to Asia/Shanghai.
+//   - at: the time in RFC 3339 or Unix seconds, defaults to now.
+func Progress(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+
+	params := r.URL.Query()
+
+	period := params.Get("period")
+
+	if period == "" {
+		period = query.PeriodYear
+	}
+
+	if !apiPeriods[period] {
+		writeAPIError(w, http.StatusBadRequest, "parameter `period` is invalid.")
+		return
+	}
+
+	tz := params.Get("tz")
+
+	if tz == "" {
+		tz = defaultTimezone
+	}
+
+	loc, err := time.LoadLocation(tz)
+
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "parameter `tz` is invalid.")
+		return
+	}
+
+	at := time.Now()
+
+	if value := params.Get("at"); value != "" {
+		if at, err = parseAPITime(value); err != nil {
+			writeAPIError(w, http.StatusBadRequest, "parameter `at` is invalid.")
+			return
+		}
+
+		// The progress at a specified time never changes.
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+	}
+
+	res := progressOf(period, at.In(loc))
+	res.Timezone = tz
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	if err := encoder.Encode(res); err != nil {
+		log.WithError(err).Error("encode progress")
+	}
+}
+
+// progressOf returns the progress of the period containing at, in the location of at.
+func progressOf(period string, at time.Time) progressResponse {
+	d := timeline.In(query.NewPeriod(period, timeline.Wall(at)).Range, at.Location())
+
+	total := d[1].Sub(d[0])
+	elapsed := at.Sub(d[0])
+	ratio := math.Round(float64(elapsed)/float64(total)*10000) / 10000
+
+	return progressResponse{
+		Period:    period,
+		At:        at.Truncate(time.Second),
+		Start:     d[0],
+		End:       d[1],
+		Ratio:     ratio,
+		Percent:   math.Round(ratio*10000) / 100,
+		Elapsed:   int64(elapsed / time.Second),
+		Remaining: int64(d[1].Sub(at) / time.Second),
+		Bar:       article.GenerateBar(math.Floor(ratio * 100)),
+	}
+}
+
+// parseAPITime parses a time in RFC 3339 or Unix seconds.
+func parseAPITime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// OpenAPI serves the OpenAPI description of the API.
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Write(openAPISpec)
+}
+
+// Preflight answers the CORS preflight requests of the API.
+func Preflight(w http.ResponseWriter, r *http.Request) {
+	setCORSHeaders(w)
+
+	w.Header().Set("Access-Control-Max-Age", "86400")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// setCORSHeaders allows the API to be called from any origin, it's public and
+// doesn't use credentials.
+func setCORSHeaders(w http.ResponseWriter) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	json.NewEncoder(w).Encode(apiError{message})
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/badge"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/query"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+)
+
+// BadgePrefix is the prefix of the paths of badges.
+const BadgePrefix = "/badge/"
+
+// Badge renders the badge of the progress of a period, the path is
+// `/badge/{period}.svg` or `/badge/{period}.png`, period is one of year, quarter,
+// month, week and day.
+//
+// Query parameters:
+//   - style: flat, flat-square, progress or text, defaults to flat.
+//   - color, labelColor: hex colors, e.g. `88cb39`.
+//   - label: the label, defaults to the name of the period in the locale.
+//   - locale: the locale of the label and the percentage, defaults to the account's.
+//   - tz: the IANA time zone, defaults to Asia/Shanghai.
+//
+// The badge is cached until the percentage changes.
+func Badge(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, BadgePrefix)
+	ext := path.Ext(name)
+	period := strings.TrimSuffix(name, ext)
+
+	if !apiPeriods[period] || ext != ".svg" && ext != ".png" {
+		http.NotFound(w, r)
+		return
+	}
+
+	params := r.URL.Query()
+
+	b := badge.Badge{
+		Style:      params.Get("style"),
+		LabelColor: badge.LabelColor,
+		Color:      badge.Color,
+	}
+
+	if b.Style == "" {
+		b.Style = badge.StyleFlat
+	}
+
+	if !isBadgeStyle(b.Style) {
+		http.Error(w, "parameter `style` is invalid.", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+
+	if value := params.Get("color"); value != "" {
+		if b.Color, err = badge.ParseColor(value); err != nil {
+			http.Error(w, "parameter `color` is invalid.", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if value := params.Get("labelColor"); value != "" {
+		if b.LabelColor, err = badge.ParseColor(value); err != nil {
+			http.Error(w, "parameter `labelColor` is invalid.", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tz := params.Get("tz")
+
+	if tz == "" {
+		tz = defaultTimezone
+	}
+
+	loc, err := time.LoadLocation(tz)
+
+	if err != nil {
+		http.Error(w, "parameter `tz` is invalid.", http.StatusBadRequest)
+		return
+	}
+
+	locale := params.Get("locale")
+
+	if locale == "" {
+		locale = Config.App.Locale
+	}
+
+	locale = i18n.Match(locale)
+
+	now := time.Now().In(loc)
+	progress := progressOf(period, now)
+	p := math.Floor(progress.Ratio * 100)
+	q := query.NewPeriod(period, timeline.Wall(now))
+
+	b.Progress = progress.Ratio
+	b.Message = i18n.FormatPercent(locale, p)
+	b.Bar = progress.Bar
+	b.Label = params.Get("label")
+
+	if b.Label == "" {
+		b.Label = periodName(q, locale)
+
+		if ext == ".png" && !badge.Printable(b.Label) {
+			b.Label = badgeLabel(q)
+		}
+	}
+
+	var body []byte
+
+	if ext == ".svg" {
+		body, err = b.SVG()
+		w.Header().Set("Content-Type", "image/svg+xml; charset=utf-8")
+	} else {
+		var buf bytes.Buffer
+
+		err = b.WritePNG(&buf)
+		body = buf.Bytes()
+		w.Header().Set("Content-Type", "image/png")
+	}
+
+	if err != nil {
+		log.WithError(err).Error("render badge")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The badge changes when the percentage reaches the next one.
+	next := progress.Start.Add(time.Duration(float64(progress.End.Sub(progress.Start)) * (p + 1) / 100))
+	maxAge := int(math.Ceil(next.Sub(now).Seconds()))
+
+	if maxAge < 1 {
+		maxAge = 1
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("Expires", next.UTC().Format(http.TimeFormat))
+	w.Write(body)
+}
+
+func isBadgeStyle(style string) bool {
+	for _, s := range badge.Styles {
+		if s == style {
+			return true
+		}
+	}
+
+	return false
+}
+
+// badgeLabel returns the label of q in digits, which is printable on PNG badges,
+// e.g. `2026-10` of a month.
+func badgeLabel(q *query.Query) string {
+	start := q.Range[0]
+
+	switch q.Period {
+	case query.PeriodQuarter:
+		return start.Format("2006") + " Q" + strconv.Itoa((int(start.Month())+2)/3)
+	case query.PeriodMonth:
+		return start.Format("2006-01")
+	case query.PeriodWeek:
+		year, week := start.ISOWeek()
+		return strconv.Itoa(year) + " W" + strconv.Itoa(week)
+	case query.PeriodDay:
+		return start.Format("2006-01-02")
+	default:
+		return start.Format("2006")
+	}
+}
@@ -6,13 +6,14 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/apex/log"
 
 	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
 	"github.com/sqrthree/progressbar201X/internal/timeline"
 	"github.com/sqrthree/progressbar201X/internal/wechat"
 )
@@ -28,30 +29,50 @@ func Pong(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	expectedSignature := wechat.Sign(Config.Wechat.Token, timestamp, nonce, "")
-
-	if signature != expectedSignature {
+	if !wechat.CheckSignature(signature, Config.Wechat.Token, timestamp, nonce, "") {
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
 
+	if err := checkReplay(r, timestamp, nonce, []byte(echostr)); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
 	fmt.Fprintln(w, echostr)
 }
 
+// isEncrypted reports whether the message of encryptType should be decrypted
+// in the configured mode, ok is false if the message is not accepted.
+func isEncrypted(encryptType string) (encrypted, ok bool) {
+	aes := encryptType == "aes"
+
+	switch Config.Wechat.EncryptMode {
+	case wechat.EncryptModePlaintext:
+		// A message in compatible mode has plaintext fields as well.
+		return false, true
+	case wechat.EncryptModeCompatible:
+		return aes && Config.Wechat.AESKey != "", true
+	default:
+		return true, aes
+	}
+}
+
 func HandleEvents(w http.ResponseWriter, r *http.Request) {
 	encryptType := r.URL.Query().Get("encrypt_type")
 	timestamp := r.URL.Query().Get("timestamp")
 	nonce := r.URL.Query().Get("nonce")
 	signature := r.URL.Query().Get("signature")
+	msgSignature := r.URL.Query().Get("msg_signature")
+
+	encrypted, ok := isEncrypted(encryptType)
 
-	if encryptType != "aes" {
+	if !ok {
 		http.Error(w, "Unsupported encryption type", http.StatusBadRequest)
 		return
 	}
 
-	expectedSignature := wechat.Sign(Config.Wechat.Token, timestamp, nonce, "")
-
-	if signature != expectedSignature {
+	if !wechat.CheckSignature(signature, Config.Wechat.Token, timestamp, nonce, "") {
 		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 		return
 	}
@@ -63,33 +84,40 @@ func HandleEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	message, err := wechat.ParseXML(body)
-
-	if err != nil {
-		log.WithError(err).Error("parse cryptographic xml")
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if err := checkReplay(r, timestamp, nonce, body); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
 		return
 	}
 
-	encrypt, ok := message["Encrypt"].(string)
+	rawXMLMsg := body
 
-	if !ok {
-		log.Error("stringify encrypt")
-		http.Error(w, "Invalid value of encrypt", http.StatusBadRequest)
-		return
-	}
+	if encrypted {
+		envelope, err := wechat.ParseEncryptedMessage(body)
 
-	_, rawXMLMsg, err := wechat.DecryptMsg(Config.Wechat.AppId, encrypt, Config.Wechat.AESKey)
+		if err != nil {
+			log.WithError(err).Error("parse cryptographic xml")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 
-	if err != nil {
-		log.WithError(err).Error("decrypt message")
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
+		if !wechat.CheckSignature(msgSignature, Config.Wechat.Token, timestamp, nonce, envelope.Encrypt) {
+			log.Error("invalid msg_signature")
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+			return
+		}
+
+		_, rawXMLMsg, err = wechat.DecryptMsg(Config.Wechat.AppId, envelope.Encrypt, Config.Wechat.AESKey)
+
+		if err != nil {
+			log.WithError(err).Error("decrypt message")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
 	}
 
 	log.WithField("rawXMLMsg", string(rawXMLMsg)).Debug("rawXMLMsg")
 
-	data, err := wechat.ParseXML(rawXMLMsg)
+	message, err := wechat.ParseMessage(rawXMLMsg)
 
 	if err != nil {
 		log.WithError(err).Error("parse unencrypted xml")
@@ -97,31 +125,33 @@ func HandleEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var contentOfResponse string
+	header := message.Header()
 
-	switch data["EventKey"] {
-	case "month":
-		contentOfResponse, err = responseOfEventMonth()
-	case "week":
-		contentOfResponse, err = responseOfEventWeek()
-	default:
-		log.Error("Unsupported event type")
-		http.Error(w, "Unsupported event type", http.StatusBadRequest)
+	// Errors are logged by the middleware. Reply `success` rather than an HTTP error
+	// to messages without reply, otherwise WeChat retries the message.
+	reply, _ := Router.Dispatch(message)
+
+	if reply == nil {
+		fmt.Fprint(w, "success")
 		return
 	}
 
+	rawXMLResponse, err := wechat.MarshalReply(reply, header.FromUserName, header.ToUserName, time.Now().Unix())
+
 	if err != nil {
-		log.WithError(err).Error("get progress of current month")
-		http.Error(w, "Unsupported event type", http.StatusBadRequest)
+		log.WithError(err).Error("marshal reply")
+		fmt.Fprint(w, "success")
 		return
 	}
 
-	random := RandomStr(16)
-	timestampOfTheMoment := strconv.Itoa(int(time.Now().Unix()))
-	rawXMLResponse := []byte(fmt.Sprintf("<xml><ToUserName>%s</ToUserName><FromUserName>%s</FromUserName><CreateTime>%s</CreateTime><MsgType>text</MsgType><Content>%s</Content></xml>", value2CDATA(data["FromUserName"]), value2CDATA(data["ToUserName"]), value2CDATA(timestampOfTheMoment), value2CDATA(contentOfResponse)))
-
 	log.WithField("rawXMLResponse", string(rawXMLResponse)).Debug("raw response XML")
 
+	if !encrypted {
+		w.Write(rawXMLResponse)
+		return
+	}
+
+	random := RandomStr(16)
 	ciphertext, err := wechat.EncryptMsg(random, rawXMLResponse, Config.Wechat.AppId, Config.Wechat.AESKey)
 
 	if err != nil {
@@ -130,15 +160,17 @@ func HandleEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	msgSignature := wechat.Sign(Config.Wechat.Token, timestamp, nonce, string(ciphertext))
+	replySignature := wechat.Sign(Config.Wechat.Token, timestamp, nonce, string(ciphertext))
 
-	XMLResponse := fmt.Sprintf("<xml><Encrypt>%s</Encrypt><MsgSignature>%s</MsgSignature><TimeStamp>%s</TimeStamp><Nonce>%s</Nonce></xml>", value2CDATA(string(ciphertext)), value2CDATA(msgSignature), value2CDATA(timestamp), value2CDATA(nonce))
+	XMLResponse, err := wechat.MarshalEncryptedReply(string(ciphertext), replySignature, timestamp, nonce)
 
-	fmt.Fprintln(w, XMLResponse)
-}
+	if err != nil {
+		log.WithError(err).Error("marshal encrypted reply")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-func value2CDATA(v interface{}) string {
-	return fmt.Sprintf("<![CDATA[%s]]>", v.(string))
+	w.Write(XMLResponse)
 }
 
 func RandomStr(length int) []byte {
@@ -155,7 +187,22 @@ func RandomStr(length int) []byte {
 	return result
 }
 
-func responseOfEventMonth() (string, error) {
+// localeOf returns the locale of the follower, or the locale of the account if not set.
+func localeOf(openId string) string {
+	locale, err := store.Default().LocaleOf(openId)
+
+	if err != nil {
+		log.WithError(err).Warn("get locale of follower")
+	}
+
+	if locale == "" {
+		locale = Config.App.Locale
+	}
+
+	return i18n.Match(locale)
+}
+
+func responseOfEventMonth(locale string) (string, error) {
 	progress, err := getProgressOfCurrentMonth()
 
 	if err != nil {
@@ -164,7 +211,7 @@ func responseOfEventMonth() (string, error) {
 
 	p := math.Floor(progress * 100)
 
-	return fmt.Sprintf("本月已经走过了 %v%s。", p, "%"), nil
+	return i18n.T(locale, "reply.month", i18n.FormatPercent(locale, p)), nil
 }
 
 func getProgressOfCurrentMonth() (progress float64, err error) {
@@ -174,7 +221,7 @@ func getProgressOfCurrentMonth() (progress float64, err error) {
 	return
 }
 
-func responseOfEventWeek() (string, error) {
+func responseOfEventWeek(locale string) (string, error) {
 	progress, err := getProgressOfCurrentWeek()
 
 	if err != nil {
@@ -183,7 +230,7 @@ func responseOfEventWeek() (string, error) {
 
 	p := math.Floor(progress * 100)
 
-	return fmt.Sprintf("本周已经走过了 %v%s。", p, "%"), nil
+	return i18n.T(locale, "reply.week", i18n.FormatPercent(locale, p)), nil
 }
 
 func getProgressOfCurrentWeek() (progress float64, err error) {
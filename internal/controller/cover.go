@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/cache"
+	"github.com/sqrthree/progressbar201X/internal/cover"
+)
+
+// maxCoverSize is the maximum width and height of covers.
+const maxCoverSize = 1200
+
+// covers caches the rendered covers by the query, the endpoint is public.
+var covers = cache.New(256)
+
+// coverTTL is the time to keep rendered covers in the cache.
+const coverTTL = 24 * time.Hour
+
+// Cover renders the cover image of a percentage as PNG.
+//
+// Query parameters:
+//   - p: the percentage in [0, 100], it's rounded to 2 decimals.
+//   - w, h: the size of the image, defaults to the size of covers of WeChat.
+func Cover(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	p, err := strconv.ParseFloat(query.Get("p"), 64)
+
+	if err != nil || p < 0 || p > 100 {
+		http.Error(w, "parameter `p` is invalid.", http.StatusBadRequest)
+		return
+	}
+
+	width, ok := parseCoverSize(query.Get("w"), cover.Width)
+
+	if !ok {
+		http.Error(w, "parameter `w` is invalid.", http.StatusBadRequest)
+		return
+	}
+
+	height, ok := parseCoverSize(query.Get("h"), cover.Height)
+
+	if !ok {
+		http.Error(w, "parameter `h` is invalid.", http.StatusBadRequest)
+		return
+	}
+
+	p = math.Round(p*100) / 100
+
+	key := strconv.FormatFloat(p, 'f', -1, 64) + "#" + strconv.Itoa(width) + "x" + strconv.Itoa(height)
+	body, ok := covers.Get(key)
+
+	if !ok {
+		var buf bytes.Buffer
+
+		if err := cover.WritePNG(&buf, p, width, height, cover.DefaultStyle); err != nil {
+			log.WithError(err).Error("render cover")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		body = buf.Bytes()
+		covers.Set(key, body, coverTTL)
+	}
+
+	// The image only depends on the query, it never changes.
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	w.Write(body)
+}
+
+func parseCoverSize(value string, defaultValue int) (int, bool) {
+	if value == "" {
+		return defaultValue, true
+	}
+
+	size, err := strconv.Atoi(value)
+
+	if err != nil || size <= 0 || size > maxCoverSize {
+		return 0, false
+	}
+
+	return size, true
+}
+
+// coverURL returns the absolute URL of the cover of p,
+// it's empty if `Config.Server.BaseURL` is not set.
+func coverURL(p float64, width, height int) string {
+	base := baseURL()
+
+	if base == "" {
+		return ""
+	}
+
+	return base + "/cover.png?p=" + strconv.FormatFloat(p, 'f', -1, 64) +
+		"&w=" + strconv.Itoa(width) + "&h=" + strconv.Itoa(height)
+}
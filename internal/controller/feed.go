@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/feed"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
+)
+
+// feedId is the tag URI of the feed, the ids of items are prefixed by it.
+const feedId = "tag:github.com,2018:sqrthree/progressbar201X"
+
+// RSSFeed serves the broadcast history as RSS 2.0.
+func RSSFeed(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "/feed.xml", feed.ContentTypeRSS, feed.RSS)
+}
+
+// AtomFeed serves the broadcast history as Atom 1.0.
+func AtomFeed(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "/atom.xml", feed.ContentTypeAtom, feed.Atom)
+}
+
+// JSONFeed serves the broadcast history as JSON Feed 1.1.
+func JSONFeed(w http.ResponseWriter, r *http.Request) {
+	serveFeed(w, r, "/feed.json", feed.ContentTypeJSON, feed.JSON)
+}
+
+// serveFeed serves the feed of path encoded by encode. Conditional requests are
+// answered by `http.ServeContent`, by the ETag of the body and the time of the
+// latest broadcast.
+func serveFeed(w http.ResponseWriter, r *http.Request, path, contentType string, encode func(*feed.Feed) ([]byte, error)) {
+	f, err := historyFeed(path)
+
+	if err != nil {
+		log.WithError(err).Error("get histories")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	body, err := encode(f)
+
+	if err != nil {
+		log.WithError(err).Error("encode feed")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha1.Sum(body)
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:10])+`"`)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	http.ServeContent(w, r, "", f.Updated, bytes.NewReader(body))
+}
+
+// historyFeed returns the feed of the latest `Config.Feed.Limit` broadcasts,
+// path is the path of the feed on the server.
+func historyFeed(path string) (*feed.Feed, error) {
+	histories, err := store.Default().Histories()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if limit := Config.Feed.Limit; limit > 0 && len(histories) > limit {
+		histories = histories[:limit]
+	}
+
+	base := baseURL()
+
+	f := &feed.Feed{
+		Id:          feedId,
+		Title:       Config.Feed.Title,
+		Description: Config.Feed.Description,
+		Language:    i18n.Match(Config.App.Locale),
+	}
+
+	if base != "" {
+		f.Link = base + "/"
+		f.FeedURL = base + path
+	}
+
+	for _, h := range histories {
+		published := h.PublishedAt.UTC().Truncate(time.Second)
+
+		if published.After(f.Updated) {
+			f.Updated = published
+		}
+
+		f.Items = append(f.Items, feed.Item{
+			Id:        feedId + "/" + h.Date,
+			Title:     h.Title,
+			Summary:   h.Digest,
+			Content:   h.Content,
+			Published: published,
+		})
+	}
+
+	return f, nil
+}
@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"strings"
+
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
+)
+
+// languageCommands are the words of the command to set the locale of a follower,
+// e.g. `language en` or `语言 zh-TW`.
+var languageCommands = map[string]bool{
+	"language": true,
+	"lang":     true,
+	"语言":       true,
+	"語言":       true,
+	"言語":       true,
+}
+
+// parseLanguageCommand parses the command to set the locale, tag is empty if the
+// command has no locale, ok is false if content isn't the command.
+func parseLanguageCommand(content string) (tag string, ok bool) {
+	fields := strings.Fields(content)
+
+	if len(fields) == 0 || len(fields) > 2 || !languageCommands[strings.ToLower(fields[0])] {
+		return "", false
+	}
+
+	if len(fields) == 2 {
+		tag = fields[1]
+	}
+
+	return tag, true
+}
+
+// handleLanguageCommand sets the locale of the follower to the one matching tag,
+// and replies in it, or replies the usage if tag isn't supported.
+func handleLanguageCommand(openId, tag, locale string) (string, error) {
+	if !i18n.Supports(tag) {
+		return i18n.T(locale, "language.usage", strings.Join(i18n.Locales, ", ")), nil
+	}
+
+	locale = i18n.Match(tag)
+
+	if err := store.Default().SetLocale(openId, locale); err != nil {
+		return "", err
+	}
+
+	return i18n.T(locale, "language.set"), nil
+}
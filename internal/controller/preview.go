@@ -0,0 +1,263 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"html/template"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+type previewLimit struct {
+	Name  string
+	Value int
+	Limit int
+}
+
+func (l previewLimit) Exceeded() bool {
+	return l.Value > l.Limit
+}
+
+type previewPage struct {
+	At        string
+	Progress  string
+	Template  string
+	Quote     string
+	Locale    string
+	Locales   []string
+	Templates []string
+	Quotes    []article.ReferenceOption
+	Article   *article.Article
+	Content   template.HTML
+	Limits    []previewLimit
+	Error     string
+}
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Preview</title>
+<style>
+body { margin: 0; padding: 20px; background: #ededed; font-family: -apple-system, "PingFang SC", "Microsoft YaHei", sans-serif; display: flex; flex-wrap: wrap; gap: 20px; align-items: flex-start; }
+form, table { background: #fff; padding: 12px; border-radius: 6px; }
+form label { display: block; margin-bottom: 8px; font-size: 14px; }
+.phone { width: 375px; height: 760px; border: 12px solid #111; border-radius: 36px; background: #fff; overflow-y: auto; }
+.phone header { padding: 10px; text-align: center; font-size: 15px; background: #f7f7f7; border-bottom: 1px solid #e5e5e5; position: sticky; top: 0; }
+.phone .rich { padding: 20px 16px; }
+.phone h2 { margin: 0 0 8px; font-size: 22px; line-height: 1.4; }
+.phone .meta { color: #576b95; font-size: 15px; margin-bottom: 20px; }
+.exceeded { color: #e64340; font-weight: bold; }
+.error { color: #e64340; }
+td, th { padding: 4px 8px; text-align: left; font-size: 14px; }
+</style>
+</head>
+<body>
+<form method="GET">
+  <label>at <input name="at" value="{{.At}}" placeholder="2006-01-02 or RFC 3339"></label>
+  <label>progress <input name="progress" value="{{.Progress}}" placeholder="0 ~ 1"></label>
+  <label>template
+    <select name="template">
+    {{range .Templates}}<option value="{{.}}"{{if eq . $.Template}} selected{{end}}>{{.}}</option>{{end}}
+    </select>
+  </label>
+  <label>locale
+    <select name="locale">
+    {{range .Locales}}<option value="{{.}}"{{if eq . $.Locale}} selected{{end}}>{{.}}</option>{{end}}
+    </select>
+  </label>
+  <label>quote
+    <select name="quote">
+      <option value="">random</option>
+    {{range $i, $q := .Quotes}}<option value="{{$i}}"{{if eq (print $i) $.Quote}} selected{{end}}>{{$i}}. {{$q.Author}} {{$q.Reference}}</option>{{end}}
+    </select>
+  </label>
+  <button type="submit">Preview</button>
+  {{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+</form>
+{{if .Article}}
+<div class="phone">
+  <header>progressbar201X</header>
+  <div class="rich">
+    <h2>{{.Article.Title}}</h2>
+    <div class="meta">progressbar201X</div>
+    {{.Content}}
+  </div>
+</div>
+<table>
+  <tr><th>title</th><td>{{.Article.Title}}</td></tr>
+  <tr><th>digest</th><td>{{.Article.Digest}}</td></tr>
+  {{range .Limits}}<tr><th>{{.Name}}</th><td{{if .Exceeded}} class="exceeded"{{end}}>{{.Value}} / {{.Limit}}</td></tr>{{end}}
+</table>
+{{end}}
+</body>
+</html>`))
+
+// Preview renders the article of the specified time in a WeChat-like frame.
+//
+// Query parameters:
+//   - at: the time of the article, `2006-01-02` or RFC 3339, defaults to now.
+//   - progress: the progress in [0, 1], defaults to the progress of the year at `at`.
+//   - template: the name of the article template.
+//   - quote: the index of the quotation, defaults to a random one.
+//   - locale: the locale of the article, defaults to the locale of the account.
+func Preview(w http.ResponseWriter, r *http.Request) {
+	if !authorizePreview(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+
+	page := previewPage{
+		At:       query.Get("at"),
+		Progress: query.Get("progress"),
+		Template: query.Get("template"),
+		Quote:    query.Get("quote"),
+		Locale:   query.Get("locale"),
+		Locales:  i18n.Locales,
+	}
+
+	if page.Locale == "" {
+		page.Locale = Config.App.Locale
+	}
+
+	if page.Template == "" {
+		page.Template = article.DefaultTemplate
+	}
+
+	templates, err := article.Templates()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	quotations, err := article.Quotations()
+
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	page.Templates = templates
+	page.Quotes = quotations.References
+
+	a, err := previewArticle(page)
+
+	if err != nil {
+		log.WithError(err).Warn("preview article")
+		page.Error = err.Error()
+	} else {
+		page.Article = a
+		page.Content = template.HTML(a.Content)
+		page.Limits = []previewLimit{
+			{"title length", utf8.RuneCountInString(a.Title), wechat.MaxArticleTitleLength},
+			{"digest length", utf8.RuneCountInString(a.Digest), wechat.MaxArticleDigestLength},
+			{"content length", utf8.RuneCountInString(a.Content), wechat.MaxArticleContentLength},
+			{"content size", len(a.Content), wechat.MaxArticleContentSize},
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+
+	if err := previewTemplate.Execute(w, page); err != nil {
+		log.WithError(err).Error("render preview")
+	}
+}
+
+// authorizePreview checks the basic auth credentials of the request.
+func authorizePreview(w http.ResponseWriter, r *http.Request) bool {
+	if Config.Preview.Password == "" {
+		http.NotFound(w, r)
+		return false
+	}
+
+	username, password, ok := r.BasicAuth()
+
+	if ok {
+		usernameMatched := subtle.ConstantTimeCompare([]byte(username), []byte(Config.Preview.Username)) == 1
+		passwordMatched := subtle.ConstantTimeCompare([]byte(password), []byte(Config.Preview.Password)) == 1
+
+		if usernameMatched && passwordMatched {
+			return true
+		}
+	}
+
+	w.Header().Set("WWW-Authenticate", `Basic realm="preview"`)
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+	return false
+}
+
+// previewArticle creates the article as `progressbar201X.NewArticle` does.
+func previewArticle(page previewPage) (*article.Article, error) {
+	at, err := parsePreviewTime(page.At)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var progress float64
+
+	if page.Progress != "" {
+		progress, err = strconv.ParseFloat(page.Progress, 64)
+
+		if err == nil && (progress < 0 || progress > 1) {
+			err = strconv.ErrRange
+		}
+	} else {
+		progress, err = timeline.NewWithYear(at)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	quote := article.Random
+
+	if page.Quote != "" {
+		quote, err = strconv.Atoi(page.Quote)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return article.NewWithOptions(at.Year(), math.Floor(progress*100), article.Options{
+		Template:  page.Template,
+		Reference: quote,
+		Digest:    article.Random,
+		Locale:    page.Locale,
+	})
+}
+
+// parsePreviewTime parses the value of `at`, the result is shifted to Beijing time
+// in the same way as the broadcast does.
+func parsePreviewTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC().Add(8 * time.Hour), nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+
+	if err != nil {
+		return t, err
+	}
+
+	return t.UTC().Add(8 * time.Hour), nil
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"math"
+	"time"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/query"
+	"github.com/sqrthree/progressbar201X/internal/reminder"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// handleText replies the progress of the period or the countdown queried by the
+// text, see the query package, or handles the reminder commands, see the reminder
+// package, or the command to set the locale of the follower, e.g. `language en`.
+// Other texts are handled by fallback.
+func handleText(m wechat.Message) (wechat.Reply, error) {
+	text, ok := m.(*wechat.TextMessage)
+
+	if !ok {
+		return fallback(m)
+	}
+
+	locale := localeOf(text.FromUserName)
+	now := time.Now().UTC().Add(8 * time.Hour)
+
+	if tag, ok := parseLanguageCommand(text.Content); ok {
+		content, err := handleLanguageCommand(text.FromUserName, tag, locale)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return wechat.TextReply{Content: content}, nil
+	}
+
+	cmd, err := reminder.Parse(text.Content)
+
+	if err == reminder.ErrInvalid {
+		return wechat.TextReply{Content: i18n.T(locale, "reminder.usage")}, nil
+	}
+
+	if err == nil {
+		content, err := handleReminderCommand(text.FromUserName, cmd, now, locale)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return wechat.TextReply{Content: content}, nil
+	}
+
+	q, err := query.Parse(text.Content, now)
+
+	if err == query.ErrNotQuery {
+		return fallback(m)
+	}
+
+	if err != nil {
+		return wechat.TextReply{Content: i18n.T(locale, "query.invalid")}, nil
+	}
+
+	return wechat.TextReply{Content: responseOfQuery(q, now, locale)}, nil
+}
+
+func responseOfQuery(q *query.Query, now time.Time, locale string) string {
+	if q.Kind == query.KindCountdown {
+		return responseOfCountdown(q, now, locale)
+	}
+
+	p := math.Floor(timeline.Progress(now, q.Range) * 100)
+	days := timeline.DaysLeft(now, q.Range[1])
+
+	return i18n.T(locale, "query.progress",
+		periodName(q, locale),
+		i18n.FormatPercent(locale, p),
+		article.GenerateBar(p),
+		i18n.N(locale, "days", days),
+	)
+}
+
+func responseOfCountdown(q *query.Query, now time.Time, locale string) string {
+	today := timeline.Day(now)[0]
+
+	// The last occurrence of a recurring target may be today.
+	if q.Recurring && q.Range[0].Equal(today) || q.Target.Equal(today) {
+		return i18n.T(locale, "query.today", q.Label)
+	}
+
+	if q.Target.Before(today) {
+		return i18n.T(locale, "query.passed", q.Label, i18n.N(locale, "days", timeline.DaysLeft(q.Target, today)))
+	}
+
+	content := i18n.T(locale, "query.countdown", q.Label, i18n.N(locale, "days", timeline.DaysLeft(today, q.Target)))
+
+	if q.Recurring {
+		p := math.Floor(timeline.Progress(now, q.Range) * 100)
+		content += "\n" + article.GenerateBar(p) + " " + i18n.FormatPercent(locale, p)
+	}
+
+	return content
+}
+
+func periodName(q *query.Query, locale string) string {
+	start := q.Range[0]
+
+	switch q.Period {
+	case query.PeriodDay:
+		return i18n.T(locale, "day.name")
+	case query.PeriodWeek:
+		return i18n.T(locale, "week.name")
+	case query.PeriodMonth:
+		return i18n.T(locale, "month.name", start.Year(), int(start.Month()), start.Month().String())
+	case query.PeriodQuarter:
+		return i18n.T(locale, "quarter.name", start.Year(), (int(start.Month())+2)/3)
+	case query.PeriodYear:
+		return i18n.T(locale, "year.name", start.Year())
+	default:
+		return q.Label
+	}
+}
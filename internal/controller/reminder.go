@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/query"
+	"github.com/sqrthree/progressbar201X/internal/reminder"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+	"github.com/sqrthree/progressbar201X/internal/wechat/mp"
+)
+
+// interactiveEvents open the window of customer service messages, besides messages.
+var interactiveEvents = map[string]bool{
+	strings.ToUpper(wechat.EventSubscribe): true,
+	wechat.EventScan:                       true,
+	wechat.EventClick:                      true,
+	"SCANCODE_PUSH":                        true,
+	"SCANCODE_WAITMSG":                     true,
+}
+
+// trackInteraction records the last interaction of followers, so reminders are
+// only sent within `wechat.CustomMessageWindow`.
+func trackInteraction() mp.Middleware {
+	return func(next mp.HandlerFunc) mp.HandlerFunc {
+		return func(m wechat.Message) (wechat.Reply, error) {
+			header := m.Header()
+
+			if header.MsgType != wechat.MsgTypeEvent || interactiveEvents[strings.ToUpper(header.Event)] {
+				if err := store.Default().SetLastInteraction(header.FromUserName, time.Unix(header.CreateTime, 0)); err != nil {
+					log.WithError(err).Warn("record interaction")
+				}
+			}
+
+			return next(m)
+		}
+	}
+}
+
+// handleReminderCommand adds, lists or cancels the reminders of the follower.
+func handleReminderCommand(openId string, cmd *reminder.Command, now time.Time, locale string) (string, error) {
+	s := store.Default()
+
+	switch cmd.Action {
+	case reminder.ActionAdd:
+		var content string
+
+		err := s.UpdateReminders(openId, func(reminders []store.Reminder) ([]store.Reminder, bool) {
+			if len(reminders) >= reminder.MaxReminders {
+				content = i18n.T(locale, "reminder.limit", reminder.MaxReminders)
+				return reminders, false
+			}
+
+			r := cmd.Reminder
+			r.CreatedAt = now
+
+			for _, existing := range reminders {
+				if existing.Id >= r.Id {
+					r.Id = existing.Id + 1
+				}
+			}
+
+			if r.Id == 0 {
+				r.Id = 1
+			}
+
+			// An occurrence that is already due when it's added starts from the next one.
+			if key, due := reminder.Due(r, now); due {
+				r.LastKey = key
+			}
+
+			content = i18n.T(locale, "reminder.added", describeReminder(r, locale), r.Id)
+			return append(reminders, r), true
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		return content, nil
+	case reminder.ActionList:
+		reminders, err := s.RemindersOf(openId)
+
+		if err != nil {
+			return "", err
+		}
+
+		if len(reminders) == 0 {
+			return i18n.T(locale, "reminder.empty"), nil
+		}
+
+		lines := []string{i18n.T(locale, "reminder.list")}
+
+		for _, r := range reminders {
+			lines = append(lines, i18n.T(locale, "reminder.item", r.Id, describeReminder(r, locale)))
+		}
+
+		return strings.Join(lines, "\n"), nil
+	case reminder.ActionCancel:
+		cancelled := false
+
+		err := s.UpdateReminders(openId, func(reminders []store.Reminder) ([]store.Reminder, bool) {
+			kept := reminders[:0]
+
+			for _, r := range reminders {
+				if cmd.Id != 0 && r.Id != cmd.Id {
+					kept = append(kept, r)
+				}
+			}
+
+			cancelled = len(kept) != len(reminders)
+			return kept, cancelled
+		})
+
+		if err != nil {
+			return "", err
+		}
+
+		if !cancelled {
+			return i18n.T(locale, "reminder.notfound"), nil
+		}
+
+		return i18n.T(locale, "reminder.cancelled"), nil
+	default:
+		return "", fmt.Errorf("unknown reminder action %q", cmd.Action)
+	}
+}
+
+func describeReminder(r store.Reminder, locale string) string {
+	clock := fmt.Sprintf("%02d:%02d", r.Hour, r.Minute)
+
+	switch r.Kind {
+	case reminder.KindThreshold:
+		period := i18n.T(locale, "reminder.period."+r.Period)
+		return i18n.T(locale, "reminder.desc.threshold", period, i18n.FormatPercent(locale, r.Percent))
+	case reminder.KindWeekly:
+		weekday := i18n.T(locale, fmt.Sprintf("weekday.%d", r.Weekday))
+		return i18n.T(locale, "reminder.desc.weekly", weekday, clock)
+	default:
+		return i18n.T(locale, "reminder.desc.daily", clock)
+	}
+}
+
+// reminderContent returns the message of the reminder delivered at now.
+func reminderContent(r store.Reminder, now time.Time, locale string) (string, error) {
+	if r.Kind == reminder.KindThreshold {
+		q := query.NewPeriod(r.Period, now)
+		p := math.Floor(timeline.Progress(now, q.Range) * 100)
+
+		return i18n.T(locale, "reminder.threshold", periodName(q, locale), i18n.FormatPercent(locale, p), article.GenerateBar(p)), nil
+	}
+
+	summary, err := progressSummary(now, locale)
+
+	if err != nil {
+		return "", err
+	}
+
+	return i18n.T(locale, "reminder.scheduled") + "\n\n" + summary, nil
+}
+
+// DeliverReminders sends the due reminders of all followers by send, which sends a
+// customer service message. Reminders of followers who haven't interacted with the
+// account within `wechat.CustomMessageWindow` are skipped.
+func DeliverReminders(send func(openId, content string) error) {
+	s := store.Default()
+	now := time.Now().UTC().Add(8 * time.Hour)
+
+	for _, openId := range s.ReminderFollowers() {
+		logger := log.WithField("openid", openId)
+
+		reminders, err := s.RemindersOf(openId)
+
+		if err != nil {
+			logger.WithError(err).Error("get reminders")
+			continue
+		}
+
+		inWindow, err := s.InteractedWithin(openId, wechat.CustomMessageWindow)
+
+		if err != nil {
+			logger.WithError(err).Error("get last interaction")
+			continue
+		}
+
+		// The keys of the handled reminders by id, which are saved to the reminders
+		// read again, they may be added or cancelled while sending.
+		handled := map[int]string{}
+
+		for _, r := range reminders {
+			key, due := reminder.Due(r, now)
+
+			if !due {
+				continue
+			}
+
+			if inWindow {
+				content, err := reminderContent(r, now, localeOf(openId))
+
+				if err == nil {
+					err = send(openId, content)
+				}
+
+				// Failed reminders are retried in the next tick.
+				if err != nil {
+					logger.WithError(err).WithField("id", r.Id).Error("deliver reminder")
+					continue
+				}
+
+				logger.WithField("id", r.Id).Info("deliver reminder")
+			} else {
+				logger.WithField("id", r.Id).Info("skip reminder out of the window of customer service messages")
+			}
+
+			handled[r.Id] = key
+		}
+
+		if len(handled) == 0 {
+			continue
+		}
+
+		err = s.UpdateReminders(openId, func(reminders []store.Reminder) ([]store.Reminder, bool) {
+			changed := false
+
+			for i, r := range reminders {
+				if key, ok := handled[r.Id]; ok {
+					reminders[i].LastKey = key
+					changed = true
+				}
+			}
+
+			return reminders, changed
+		})
+
+		if err != nil {
+			logger.WithError(err).Error("save reminders")
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/wechat/mp"
+)
+
+var (
+	errStaleTimestamp = errors.New("timestamp out of the window")
+	errReusedNonce    = errors.New("nonce reused")
+)
+
+var (
+	noncesMutex sync.Mutex
+	nonces      = mp.NewMemoryStore(Config.Wechat.Replay.Capacity)
+)
+
+// checkReplay rejects callbacks whose timestamp is out of the skew window, or whose
+// nonce was used in the window. A nonce reused with the same payload within the TTL
+// of `Config.Wechat.Dedupe` is a retry of WeChat, which is answered by the cached
+// reply instead; later ones are rejected, the reply may be expired by then and the
+// handlers would run again. Rejections are audited in the logs.
+func checkReplay(r *http.Request, timestamp, nonce string, payload []byte) error {
+	window := time.Duration(Config.Wechat.Replay.Window) * time.Second
+
+	if window <= 0 {
+		return nil
+	}
+
+	retry := time.Duration(Config.Wechat.Dedupe.TTL) * time.Second
+
+	err := checkNonce(timestamp, nonce, payload, window, retry)
+
+	if err != nil {
+		log.WithFields(log.Fields{
+			"remote":    r.RemoteAddr,
+			"path":      r.URL.Path,
+			"timestamp": timestamp,
+			"nonce":     nonce,
+		}).WithError(err).Warn("reject replayed callback")
+	}
+
+	return err
+}
+
+// checkNonce records the sha1 of the payload and the time of the first use of nonce,
+// a reuse with the same payload is accepted within retry of the first use.
+func checkNonce(timestamp, nonce string, payload []byte, window, retry time.Duration) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return errStaleTimestamp
+	}
+
+	skew := time.Since(time.Unix(sec, 0))
+
+	if skew > window || skew < -window {
+		return errStaleTimestamp
+	}
+
+	sum := sha1.Sum(payload)
+	now := time.Now()
+
+	noncesMutex.Lock()
+	defer noncesMutex.Unlock()
+
+	seen, ok, err := nonces.Get(nonce)
+
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		if len(seen) != len(sum)+8 || !bytes.Equal(seen[:len(sum)], sum[:]) {
+			return errReusedNonce
+		}
+
+		first := time.Unix(0, int64(binary.BigEndian.Uint64(seen[len(sum):])))
+
+		if now.Sub(first) > retry {
+			return errReusedNonce
+		}
+
+		return nil
+	}
+
+	value := make([]byte, len(sum)+8)
+	copy(value, sum[:])
+	binary.BigEndian.PutUint64(value[len(sum):], uint64(now.UnixNano()))
+
+	// The timestamp is checked before, so the nonce is kept until it's out of the window.
+	return nonces.Set(nonce, value, 2*window)
+}
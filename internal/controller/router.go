@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/cover"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+	"github.com/sqrthree/progressbar201X/internal/wechat/mp"
+)
+
+// Router routes the inbound messages of the account.
+var Router = newRouter()
+
+func newRouter() *mp.Router {
+	r := mp.NewRouter()
+
+	r.Use(mp.Recover(), mp.Logging(), mp.Dedupe(idempotencyStore(), time.Duration(Config.Wechat.Dedupe.TTL)*time.Second), trackInteraction())
+
+	r.HandleEventKey(wechat.EventClick, "month", handleMonth)
+	r.HandleEventKey(wechat.EventClick, "week", handleWeek)
+	r.Handle(wechat.MsgTypeText, handleText)
+	r.HandleEvent(wechat.EventSubscribe, handleSubscribe)
+	r.HandleEvent(wechat.EventUnsubscribe, handleUnsubscribe)
+	r.HandleEvent(wechat.EventTemplateSendJobFinish, handleTemplateSendJobFinish)
+
+	r.Fallback = fallback
+
+	return r
+}
+
+// idempotencyStore returns the cache of replies configured by `Config.Wechat.Dedupe`.
+func idempotencyStore() mp.IdempotencyStore {
+	if Config.Wechat.Dedupe.Store == "store" {
+		return store.Default().Expiring("replies")
+	}
+
+	return mp.NewMemoryStore(Config.Wechat.Dedupe.Capacity)
+}
+
+// fallback replies `Config.Wechat.FallbackReply` to unmatched messages,
+// or nothing if it's empty.
+func fallback(m wechat.Message) (wechat.Reply, error) {
+	if Config.Wechat.FallbackReply == "" {
+		return nil, nil
+	}
+
+	return wechat.TextReply{Content: Config.Wechat.FallbackReply}, nil
+}
+
+// handleMonth replies a news card with the cover of the progress of this month,
+// or a text if the cover can't be linked.
+func handleMonth(m wechat.Message) (wechat.Reply, error) {
+	locale := localeOf(m.Header().FromUserName)
+
+	progress, err := getProgressOfCurrentMonth()
+
+	if err != nil {
+		return nil, err
+	}
+
+	p := math.Floor(progress * 100)
+	content := i18n.T(locale, "reply.month", i18n.FormatPercent(locale, p))
+
+	picURL := coverURL(p, 360, 200)
+
+	if picURL == "" {
+		return wechat.TextReply{Content: content}, nil
+	}
+
+	return wechat.NewsReply{
+		Articles: []wechat.NewsArticle{
+			{
+				Title:       content,
+				Description: article.GenerateBar(p),
+				PicURL:      picURL,
+				URL:         coverURL(p, cover.Width, cover.Height),
+			},
+		},
+	}, nil
+}
+
+func handleWeek(m wechat.Message) (wechat.Reply, error) {
+	content, err := responseOfEventWeek(localeOf(m.Header().FromUserName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return wechat.TextReply{Content: content}, nil
+}
+
+// handleTemplateSendJobFinish records the result of a template message.
+func handleTemplateSendJobFinish(m wechat.Message) (wechat.Reply, error) {
+	e, ok := m.(*wechat.TemplateSendJobFinishEvent)
+
+	if !ok {
+		return nil, nil
+	}
+
+	status, reason := store.DeliverySucceeded, ""
+
+	if e.Status != store.DeliverySucceeded {
+		status, reason = store.DeliveryFailed, e.Status
+	}
+
+	ok, err := store.Default().UpdateDeliveryStatus(e.MsgId, status, reason)
+
+	if err == nil && !ok {
+		log.WithField("msgid", e.MsgId).Warn("unknown template message")
+	}
+
+	return nil, err
+}
+
+// baseURL returns `Config.Server.BaseURL` without the trailing slash.
+func baseURL() string {
+	return strings.TrimSuffix(Config.Server.BaseURL, "/")
+}
@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/cover"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// handleSubscribe records the subscription and replies the welcome message,
+// see `Config.Wechat.Welcome`.
+func handleSubscribe(m wechat.Message) (wechat.Reply, error) {
+	header := m.Header()
+
+	var scene string
+
+	if e, ok := m.(*wechat.SubscribeEvent); ok {
+		scene = strings.TrimPrefix(e.EventKey, "qrscene_")
+	}
+
+	// The follower is welcomed even if the subscription is not recorded.
+	if err := store.Default().Subscribe(header.FromUserName, scene, time.Unix(header.CreateTime, 0)); err != nil {
+		log.WithError(err).Error("record subscription")
+	}
+
+	return welcome(localeOf(header.FromUserName))
+}
+
+// handleUnsubscribe records the subscription change, nothing can be replied.
+func handleUnsubscribe(m wechat.Message) (wechat.Reply, error) {
+	header := m.Header()
+
+	return nil, store.Default().Unsubscribe(header.FromUserName, time.Unix(header.CreateTime, 0))
+}
+
+func welcome(locale string) (wechat.Reply, error) {
+	now := time.Now().UTC().Add(8 * time.Hour)
+
+	progress, err := progressSummary(now, locale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	greeting := Config.Wechat.Welcome.Greeting
+
+	if greeting == "" {
+		greeting = i18n.T(locale, "welcome.greeting")
+	}
+
+	yearProgress, _ := timeline.NewWithYear(now)
+	p := math.Floor(yearProgress * 100)
+	picURL := coverURL(p, 360, 200)
+
+	if Config.Wechat.Welcome.Type != wechat.ReplyTypeNews || picURL == "" {
+		return wechat.TextReply{Content: greeting + "\n\n" + progress}, nil
+	}
+
+	url := Config.Wechat.Welcome.URL
+
+	if url == "" {
+		url = coverURL(p, cover.Width, cover.Height)
+	}
+
+	return wechat.NewsReply{
+		Articles: []wechat.NewsArticle{
+			{
+				Title:       greeting,
+				Description: progress,
+				PicURL:      picURL,
+				URL:         url,
+			},
+		},
+	}, nil
+}
+
+// progressSummary returns the progress of the year, month and week, one per line.
+func progressSummary(now time.Time, locale string) (string, error) {
+	var percents [3]float64
+
+	for i, f := range []func(time.Time) (float64, error){timeline.NewWithYear, timeline.NewWithMonth, timeline.NewWithWeek} {
+		progress, err := f(now)
+
+		if err != nil {
+			return "", err
+		}
+
+		percents[i] = math.Floor(progress * 100)
+	}
+
+	return strings.Join([]string{
+		i18n.T(locale, "year.heading", now.Year(), i18n.FormatPercent(locale, percents[0])),
+		i18n.T(locale, "reply.month", i18n.FormatPercent(locale, percents[1])),
+		i18n.T(locale, "reply.week", i18n.FormatPercent(locale, percents[2])),
+	}, "\n"), nil
+}
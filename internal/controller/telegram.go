@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/query"
+	"github.com/sqrthree/progressbar201X/internal/telegram"
+)
+
+// TelegramWebhook answers the commands of the bot of Telegram:
+//   - /year, /month, /week: the progress of the period.
+//   - /until DATE: the countdown to the date, e.g. `12-25` or `春节`, see the query package.
+//   - /start, /help: the usage.
+//
+// Other messages are ignored. It's not found unless `Config.Telegram.Token` and
+// `Config.Telegram.Secret` are set, updates are only accepted with the secret.
+func TelegramWebhook(w http.ResponseWriter, r *http.Request) {
+	if Config.Telegram.Token == "" || Config.Telegram.Secret == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	secret := r.Header.Get(telegram.SecretTokenHeader)
+
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(Config.Telegram.Secret)) != 1 {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	var update telegram.Update
+
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid update", http.StatusBadRequest)
+		return
+	}
+
+	message := update.Message
+
+	if message == nil {
+		message = update.ChannelPost
+	}
+
+	if message == nil {
+		return
+	}
+
+	client := telegram.NewClient(Config.Telegram.Token, Config.Telegram.BaseURL, time.Duration(Config.Telegram.Timeout)*time.Second)
+
+	text, ok := responseOfTelegramCommand(message, telegramBotUsername(client), time.Now().UTC().Add(8*time.Hour))
+
+	if !ok {
+		return
+	}
+
+	// Telegram redelivers the update if it fails, so errors of sending are only logged.
+	if _, err := client.SendMessage(telegram.ChatId(message.Chat.Id), text); err != nil {
+		log.WithError(err).WithField("chat", message.Chat.Id).Error("reply telegram command")
+	}
+}
+
+var (
+	telegramBotMutex sync.Mutex
+	telegramBotName  string
+)
+
+// telegramBotUsername returns `Config.Telegram.Username`, or the username of the
+// bot got by client, which is cached once it's got. It's empty if it fails, then
+// only the commands not addressed to any bot are answered.
+func telegramBotUsername(client *telegram.Client) string {
+	if Config.Telegram.Username != "" {
+		return Config.Telegram.Username
+	}
+
+	telegramBotMutex.Lock()
+	defer telegramBotMutex.Unlock()
+
+	if telegramBotName == "" {
+		user, err := client.GetMe()
+
+		if err != nil {
+			log.WithError(err).Error("get the bot of telegram")
+			return ""
+		}
+
+		telegramBotName = user.Username
+	}
+
+	return telegramBotName
+}
+
+// responseOfTelegramCommand returns the reply of the command of m sent at now,
+// ok is false if m is not a command of the bot of username.
+func responseOfTelegramCommand(m *telegram.Message, username string, now time.Time) (text string, ok bool) {
+	command, arg, ok := m.Command(username)
+
+	if !ok {
+		return "", false
+	}
+
+	locale := Config.App.Locale
+
+	if m.From != nil && m.From.LanguageCode != "" {
+		locale = m.From.LanguageCode
+	}
+
+	locale = i18n.Match(locale)
+
+	switch command {
+	case "year":
+		return responseOfQuery(query.NewPeriod(query.PeriodYear, now), now, locale), true
+	case "month":
+		return responseOfQuery(query.NewPeriod(query.PeriodMonth, now), now, locale), true
+	case "week":
+		return responseOfQuery(query.NewPeriod(query.PeriodWeek, now), now, locale), true
+	case "until":
+		q, err := query.Parse(arg, now)
+
+		if err == nil && q.Kind == query.KindCountdown {
+			return responseOfQuery(q, now, locale), true
+		}
+
+		return i18n.T(locale, "telegram.usage"), true
+	case "start", "help":
+		return i18n.T(locale, "telegram.usage"), true
+	default:
+		return "", false
+	}
+}
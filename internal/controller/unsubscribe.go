@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/mailer"
+	"github.com/sqrthree/progressbar201X/internal/store"
+)
+
+type unsubscribePage struct {
+	Locale  string
+	Message string
+	Email   string
+	Token   string
+	Button  string
+	Confirm bool
+}
+
+var unsubscribeTemplate = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Button}}</title>
+</head>
+<body style="font-family: -apple-system, sans-serif; text-align: center; padding: 40px 20px;">
+<p>{{.Message}}</p>
+{{if .Confirm}}<form method="post">
+<input type="hidden" name="email" value="{{.Email}}">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">{{.Button}}</button>
+</form>{{end}}
+</body>
+</html>`))
+
+// Unsubscribe unsubscribes the address from the newsletter.
+//
+// The links in mails are opened by GET, which asks for confirmation, because
+// links may be prefetched by mail scanners. The address is unsubscribed by POST,
+// which is also the one-click unsubscription of mail clients, see RFC 8058.
+//
+// Parameters:
+//   - email: the address.
+//   - token: the token of the address, see `mailer.Token`.
+func Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	locale := i18n.Match(Config.App.Locale)
+
+	page := unsubscribePage{
+		Locale: locale,
+		Email:  r.FormValue("email"),
+		Token:  r.FormValue("token"),
+		Button: i18n.T(locale, "newsletter.unsubscribe"),
+	}
+
+	status := http.StatusOK
+
+	switch {
+	case Config.Mail.Secret == "" || page.Email == "" || !mailer.CheckToken(Config.Mail.Secret, page.Email, page.Token):
+		status = http.StatusBadRequest
+		page.Message = i18n.T(locale, "newsletter.invalid")
+	case r.Method == "POST":
+		if err := store.Default().UnsubscribeNewsletter(page.Email, time.Now()); err != nil {
+			log.WithError(err).Error("unsubscribe newsletter")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		log.WithField("email", page.Email).Info("unsubscribe newsletter")
+
+		page.Message = i18n.T(locale, "newsletter.unsubscribed", page.Email)
+	default:
+		page.Confirm = true
+		page.Message = i18n.T(locale, "newsletter.confirm", page.Email)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+
+	if err := unsubscribeTemplate.Execute(w, page); err != nil {
+		log.WithError(err).Error("render unsubscribe page")
+	}
+}
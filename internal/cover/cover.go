@@ -0,0 +1,161 @@
+// Package cover draws the cover images of progress, a bar with the percentage.
+package cover
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strconv"
+)
+
+// Sizes of covers used by WeChat.
+const (
+	Width  = 900
+	Height = 383
+)
+
+// Colors of the cover, taken from the article template.
+var (
+	Background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	Track      = color.RGBA{0xee, 0xee, 0xee, 0xff}
+	Fill       = color.RGBA{0x88, 0xcb, 0x39, 0xff}
+	Border     = color.RGBA{0xfd, 0xd7, 0x21, 0xff}
+	Text       = color.RGBA{0x3e, 0x3e, 0x3e, 0xff}
+)
+
+// Style holds the colors of a cover.
+type Style struct {
+	Background color.Color
+	Track      color.Color
+	Fill       color.Color
+	Border     color.Color
+	Text       color.Color
+}
+
+// DefaultStyle is the style of covers of articles.
+var DefaultStyle = Style{Background, Track, Fill, Border, Text}
+
+// glyphs is a 5x7 bitmap font of the characters used to print a percentage.
+var glyphs = map[rune][7]string{
+	'0': {".###.", "#...#", "#..##", "#.#.#", "##..#", "#...#", ".###."},
+	'1': {"..#..", ".##..", "..#..", "..#..", "..#..", "..#..", ".###."},
+	'2': {".###.", "#...#", "....#", "...#.", "..#..", ".#...", "#####"},
+	'3': {"#####", "...#.", "..#..", "...#.", "....#", "#...#", ".###."},
+	'4': {"...#.", "..##.", ".#.#.", "#..#.", "#####", "...#.", "...#."},
+	'5': {"#####", "#....", "####.", "....#", "....#", "#...#", ".###."},
+	'6': {"..##.", ".#...", "#....", "####.", "#...#", "#...#", ".###."},
+	'7': {"#####", "....#", "...#.", "..#..", ".#...", ".#...", ".#..."},
+	'8': {".###.", "#...#", "#...#", ".###.", "#...#", "#...#", ".###."},
+	'9': {".###.", "#...#", "#...#", ".####", "....#", "...#.", ".##.."},
+	'.': {".....", ".....", ".....", ".....", ".....", ".##..", ".##.."},
+	'%': {"##...", "##..#", "...#.", "..#..", ".#...", "#..##", "...##"},
+	'-': {".....", ".....", ".....", "#####", ".....", ".....", "....."},
+	'Q': {".###.", "#...#", "#...#", "#...#", "#.#.#", "#..#.", ".##.#"},
+	'W': {"#...#", "#...#", "#...#", "#.#.#", "#.#.#", "##.##", "#...#"},
+	' ': {".....", ".....", ".....", ".....", ".....", ".....", "....."},
+}
+
+// Printable reports whether all characters of s are in the font of covers.
+func Printable(s string) bool {
+	for _, r := range s {
+		if _, ok := glyphs[r]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// TextWidth returns the width of s printed by PrintText with scale.
+func TextWidth(s string, scale int) int {
+	n := len([]rune(s))
+
+	if n == 0 {
+		return 0
+	}
+
+	return n*6*scale - scale
+}
+
+// TextHeight returns the height of text printed by PrintText with scale.
+func TextHeight(scale int) int {
+	return 7 * scale
+}
+
+// Render draws the cover of p, a percentage in [0, 100].
+func Render(p float64, width, height int, style Style) *image.RGBA {
+	if p < 0 {
+		p = 0
+	} else if p > 100 {
+		p = 100
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), style.Background)
+
+	margin := width / 12
+	barHeight := height / 6
+	barTop := height * 3 / 5
+	bar := image.Rect(margin, barTop, width-margin, barTop+barHeight)
+
+	border := height / 60
+
+	if border < 1 {
+		border = 1
+	}
+
+	fill(img, bar.Inset(-border), style.Border)
+	fill(img, bar, style.Track)
+
+	filled := bar
+	filled.Max.X = bar.Min.X + int(float64(bar.Dx())*p/100)
+	fill(img, filled, style.Fill)
+
+	text := strconv.FormatFloat(p, 'f', -1, 64) + "%"
+	scale := barHeight * 2 / 7
+
+	if scale < 1 {
+		scale = 1
+	}
+
+	textWidth := TextWidth(text, scale)
+	PrintText(img, text, (width-textWidth)/2, barTop-barHeight/2-7*scale, scale, style.Text)
+
+	return img
+}
+
+// WritePNG writes the cover of p as PNG to w.
+func WritePNG(w io.Writer, p float64, width, height int, style Style) error {
+	return png.Encode(w, Render(p, width, height, style))
+}
+
+func fill(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{c}, image.Point{}, draw.Src)
+}
+
+// PrintText prints s at (x, y) with each pixel of glyphs scaled to a square of scale,
+// characters out of the font are skipped, see Printable.
+func PrintText(img draw.Image, s string, x, y, scale int, c color.Color) {
+	for _, r := range s {
+		glyph, ok := glyphs[r]
+
+		if ok {
+			for row, line := range glyph {
+				for col, pixel := range line {
+					if pixel != '#' {
+						continue
+					}
+
+					px := x + col*scale
+					py := y + row*scale
+
+					fill(img, image.Rect(px, py, px+scale, py+scale), c)
+				}
+			}
+		}
+
+		x += 6 * scale
+	}
+}
@@ -0,0 +1,259 @@
+// Package feed encodes feeds of posts as RSS 2.0, Atom 1.0 and JSON Feed 1.1.
+package feed
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"time"
+)
+
+// Content types of the formats.
+const (
+	ContentTypeRSS  = "application/rss+xml; charset=utf-8"
+	ContentTypeAtom = "application/atom+xml; charset=utf-8"
+	ContentTypeJSON = "application/feed+json; charset=utf-8"
+)
+
+// Feed is a feed of Items, the latest first.
+type Feed struct {
+	// Id identifies the feed permanently, e.g. a tag URI, it's the id of the Atom
+	// feed if neither FeedURL nor Link is set.
+	Id          string
+	Title       string
+	Description string
+	// Link is the home page, and FeedURL is the URL of the feed itself.
+	Link     string
+	FeedURL  string
+	Language string
+	Updated  time.Time
+	Items    []Item
+}
+
+// Item is a post of a feed.
+type Item struct {
+	// Id identifies the item permanently, e.g. a tag URI.
+	Id      string
+	Title   string
+	Summary string
+	// Content is HTML.
+	Content   string
+	URL       string
+	Published time.Time
+}
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	Language      string    `xml:"language,omitempty"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	AtomLink      *atomLink `xml:"atom:link,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	Content     cdata   `xml:"content:encoded"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// RSS encodes f as RSS 2.0, the content is in content:encoded.
+func RSS(f *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       f.Title,
+		Link:        f.Link,
+		Description: f.Description,
+		Language:    f.Language,
+	}
+
+	if !f.Updated.IsZero() {
+		channel.LastBuildDate = f.Updated.Format(time.RFC1123Z)
+	}
+
+	if f.FeedURL != "" {
+		channel.AtomLink = &atomLink{Href: f.FeedURL, Rel: "self", Type: "application/rss+xml"}
+	}
+
+	for _, item := range f.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.URL,
+			Description: item.Summary,
+			Content:     cdata{item.Content},
+			GUID:        rssGUID{false, item.Id},
+			PubDate:     item.Published.Format(time.RFC1123Z),
+		})
+	}
+
+	return marshalXML(rss{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		Channel:   channel,
+	})
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Lang     string      `xml:"xml:lang,attr,omitempty"`
+	Id       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Id        string     `xml:"id"`
+	Title     string     `xml:"title"`
+	Summary   string     `xml:"summary,omitempty"`
+	Content   atomText   `xml:"content"`
+	Links     []atomLink `xml:"link"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+}
+
+type atomText struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Atom encodes f as Atom 1.0, the id of the feed is FeedURL, or Link, or Id, the
+// first one which isn't empty.
+func Atom(f *Feed) ([]byte, error) {
+	feed := atomFeed{
+		Lang:     f.Language,
+		Id:       f.FeedURL,
+		Title:    f.Title,
+		Subtitle: f.Description,
+		Updated:  f.Updated.Format(time.RFC3339),
+	}
+
+	if feed.Id == "" {
+		feed.Id = f.Link
+	}
+
+	if feed.Id == "" {
+		feed.Id = f.Id
+	}
+
+	if f.Link != "" {
+		feed.Links = append(feed.Links, atomLink{Href: f.Link, Rel: "alternate"})
+	}
+
+	if f.FeedURL != "" {
+		feed.Links = append(feed.Links, atomLink{Href: f.FeedURL, Rel: "self", Type: "application/atom+xml"})
+	}
+
+	for _, item := range f.Items {
+		entry := atomEntry{
+			Id:        item.Id,
+			Title:     item.Title,
+			Summary:   item.Summary,
+			Content:   atomText{"html", item.Content},
+			Published: item.Published.Format(time.RFC3339),
+			Updated:   item.Published.Format(time.RFC3339),
+		}
+
+		if item.URL != "" {
+			entry.Links = append(entry.Links, atomLink{Href: item.URL, Rel: "alternate"})
+		}
+
+		feed.Entries = append(feed.Entries, entry)
+	}
+
+	return marshalXML(feed)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), data...), nil
+}
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url,omitempty"`
+	FeedURL     string     `json:"feed_url,omitempty"`
+	Description string     `json:"description,omitempty"`
+	Language    string     `json:"language,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonItem struct {
+	Id            string `json:"id"`
+	URL           string `json:"url,omitempty"`
+	Title         string `json:"title"`
+	Summary       string `json:"summary,omitempty"`
+	ContentHTML   string `json:"content_html"`
+	DatePublished string `json:"date_published"`
+}
+
+// JSON encodes f as JSON Feed 1.1.
+func JSON(f *Feed) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.Title,
+		HomePageURL: f.Link,
+		FeedURL:     f.FeedURL,
+		Description: f.Description,
+		Language:    f.Language,
+		Items:       []jsonItem{},
+	}
+
+	for _, item := range f.Items {
+		feed.Items = append(feed.Items, jsonItem{
+			Id:            item.Id,
+			URL:           item.URL,
+			Title:         item.Title,
+			Summary:       item.Summary,
+			ContentHTML:   item.Content,
+			DatePublished: item.Published.Format(time.RFC3339),
+		})
+	}
+
+	var buf bytes.Buffer
+
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(feed); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
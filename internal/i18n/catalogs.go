@@ -0,0 +1,258 @@
+package i18n
+
+// catalogs holds the messages of all locales. Formats always reference
+// arguments by index, so a translation may use them in any order or skip some.
+//
+// Arguments of keys:
+//   - percent: number
+//   - year.title: year, bar, percent
+//   - year.heading: year, percent
+//   - quarter.name: year, quarter
+//   - month.name: year, month, month name in English
+//   - week.name: none
+//   - period.title: period name, bar, percent
+//   - period.heading: period name, percent
+//   - reply.month, reply.week: percent
+//   - lastyear.title: title of the article
+//   - days: number of days
+//   - day.name: none
+//   - year.name: year
+//   - query.progress: period name, percent, bar, days left
+//   - query.countdown: target name, days left
+//   - query.today: target name
+//   - query.passed: target name, days passed
+//   - query.invalid: none
+//   - welcome.greeting: none
+//   - reminder.added: description, id
+//   - reminder.item: id, description
+//   - reminder.list, reminder.empty, reminder.cancelled, reminder.notfound, reminder.usage: none
+//   - reminder.limit: maximum number of reminders
+//   - reminder.threshold: period name, percent, bar
+//   - reminder.scheduled: none
+//   - telegram.usage: none
+//   - newsletter.footer, newsletter.unsubscribe, newsletter.invalid: none
+//   - newsletter.confirm, newsletter.unsubscribed: address
+//   - language.set: none
+//   - language.usage: supported locales
+//   - reminder.desc.threshold: period word, percent
+//   - reminder.desc.daily: time
+//   - reminder.desc.weekly: weekday, time
+//   - reminder.period.*, weekday.*: none, weekdays are numbered from Sunday as 0
+var catalogs = map[string]map[string]message{
+	"zh-CN": {
+		"percent":                 {Other: "%[1]s%%"},
+		"year.title":              {Other: "%[1]v 年已经走过了 %[2]s %[3]s"},
+		"year.heading":            {Other: "%[1]v 年已经走过了 %[2]s 啦"},
+		"quarter.name":            {Other: "%[1]v 年第 %[2]v 季度"},
+		"month.name":              {Other: "%[1]v 年 %[2]v 月"},
+		"week.name":               {Other: "本周"},
+		"period.title":            {Other: "%[1]s已经走过了 %[2]s %[3]s"},
+		"period.heading":          {Other: "%[1]s已经走过了 %[2]s 啦"},
+		"reply.month":             {Other: "本月已经走过了 %[1]s。"},
+		"reply.week":              {Other: "本周已经走过了 %[1]s。"},
+		"lastyear.title":          {Other: "去年今天：%[1]s"},
+		"days":                    {Other: "%[1]s 天"},
+		"day.name":                {Other: "今天"},
+		"year.name":               {Other: "%[1]v 年"},
+		"query.progress":          {Other: "%[1]s已经走过了 %[2]s\n%[3]s\n还剩 %[4]s。"},
+		"query.countdown":         {Other: "距离%[1]s还有 %[2]s。"},
+		"query.today":             {Other: "今天就是%[1]s！"},
+		"query.passed":            {Other: "%[1]s已经过去 %[2]s。"},
+		"query.invalid":           {Other: "日期范围无效。"},
+		"welcome.greeting":        {Other: "感谢关注！每天和你一起看看时间走到了哪里。"},
+		"reminder.added":          {Other: "已添加提醒 #%[2]v：%[1]s。\n回复“提醒列表”查看，回复“取消提醒 %[2]v”取消。"},
+		"reminder.item":           {Other: "#%[1]v %[2]s"},
+		"reminder.list":           {Other: "你的提醒："},
+		"reminder.empty":          {Other: "你还没有设置提醒。"},
+		"reminder.cancelled":      {Other: "已取消提醒。"},
+		"reminder.notfound":       {Other: "没有找到这个提醒。"},
+		"reminder.usage":          {Other: "没看懂这个提醒。可以这样设置：\n提醒 本月 50%%\n提醒 每周一 8:00\n提醒 每天 21:30\n回复“提醒列表”查看，回复“取消提醒 编号”取消。\n提醒仅在你 48 小时内和公众号互动过时送达。"},
+		"reminder.limit":          {Other: "最多只能设置 %[1]v 个提醒。"},
+		"reminder.threshold":      {Other: "⏰ %[1]s已经走过了 %[2]s\n%[3]s"},
+		"reminder.scheduled":      {Other: "⏰ 你的定时提醒"},
+		"telegram.usage":          {Other: "/year 今年的进度\n/month 本月的进度\n/week 本周的进度\n/until 日期 倒计时，例如 /until 12-25 或 /until 春节"},
+		"newsletter.footer":       {Other: "不想再收到这些邮件？"},
+		"newsletter.unsubscribe":  {Other: "退订"},
+		"newsletter.confirm":      {Other: "确定不再向 %[1]s 发送邮件吗？"},
+		"newsletter.unsubscribed": {Other: "已退订，%[1]s 不会再收到邮件。"},
+		"newsletter.invalid":      {Other: "退订链接无效。"},
+		"language.set":            {Other: "已切换为简体中文。"},
+		"language.usage":          {Other: "回复“语言 代码”切换语言，支持：%[1]s"},
+		"reminder.desc.threshold": {Other: "每%[1]s走到 %[2]s 时"},
+		"reminder.desc.daily":     {Other: "每天 %[1]s"},
+		"reminder.desc.weekly":    {Other: "每%[1]s %[2]s"},
+		"reminder.period.year":    {Other: "年"},
+		"reminder.period.quarter": {Other: "季度"},
+		"reminder.period.month":   {Other: "月"},
+		"reminder.period.week":    {Other: "周"},
+		"weekday.0":               {Other: "周日"},
+		"weekday.1":               {Other: "周一"},
+		"weekday.2":               {Other: "周二"},
+		"weekday.3":               {Other: "周三"},
+		"weekday.4":               {Other: "周四"},
+		"weekday.5":               {Other: "周五"},
+		"weekday.6":               {Other: "周六"},
+	},
+	"zh-TW": {
+		"percent":                 {Other: "%[1]s%%"},
+		"year.title":              {Other: "%[1]v 年已經走過了 %[2]s %[3]s"},
+		"year.heading":            {Other: "%[1]v 年已經走過了 %[2]s 啦"},
+		"quarter.name":            {Other: "%[1]v 年第 %[2]v 季"},
+		"month.name":              {Other: "%[1]v 年 %[2]v 月"},
+		"week.name":               {Other: "本週"},
+		"period.title":            {Other: "%[1]s已經走過了 %[2]s %[3]s"},
+		"period.heading":          {Other: "%[1]s已經走過了 %[2]s 啦"},
+		"reply.month":             {Other: "本月已經走過了 %[1]s。"},
+		"reply.week":              {Other: "本週已經走過了 %[1]s。"},
+		"lastyear.title":          {Other: "去年今天：%[1]s"},
+		"days":                    {Other: "%[1]s 天"},
+		"day.name":                {Other: "今天"},
+		"year.name":               {Other: "%[1]v 年"},
+		"query.progress":          {Other: "%[1]s已經走過了 %[2]s\n%[3]s\n還剩 %[4]s。"},
+		"query.countdown":         {Other: "距離%[1]s還有 %[2]s。"},
+		"query.today":             {Other: "今天就是%[1]s！"},
+		"query.passed":            {Other: "%[1]s已經過去 %[2]s。"},
+		"query.invalid":           {Other: "日期範圍無效。"},
+		"welcome.greeting":        {Other: "感謝關注！每天和你一起看看時間走到了哪裡。"},
+		"reminder.added":          {Other: "已新增提醒 #%[2]v：%[1]s。\n回覆「提醒列表」查看，回覆「取消提醒 %[2]v」取消。"},
+		"reminder.item":           {Other: "#%[1]v %[2]s"},
+		"reminder.list":           {Other: "你的提醒："},
+		"reminder.empty":          {Other: "你還沒有設定提醒。"},
+		"reminder.cancelled":      {Other: "已取消提醒。"},
+		"reminder.notfound":       {Other: "沒有找到這個提醒。"},
+		"reminder.usage":          {Other: "沒看懂這個提醒。可以這樣設定：\n提醒 本月 50%%\n提醒 每週一 8:00\n提醒 每天 21:30\n回覆「提醒列表」查看，回覆「取消提醒 編號」取消。\n提醒僅在你 48 小時內和公眾號互動過時送達。"},
+		"reminder.limit":          {Other: "最多只能設定 %[1]v 個提醒。"},
+		"reminder.threshold":      {Other: "⏰ %[1]s已經走過了 %[2]s\n%[3]s"},
+		"reminder.scheduled":      {Other: "⏰ 你的定時提醒"},
+		"telegram.usage":          {Other: "/year 今年的進度\n/month 本月的進度\n/week 本週的進度\n/until 日期 倒數計時，例如 /until 12-25 或 /until 春節"},
+		"newsletter.footer":       {Other: "不想再收到這些郵件？"},
+		"newsletter.unsubscribe":  {Other: "取消訂閱"},
+		"newsletter.confirm":      {Other: "確定不再寄送郵件到 %[1]s 嗎？"},
+		"newsletter.unsubscribed": {Other: "已取消訂閱，%[1]s 不會再收到郵件。"},
+		"newsletter.invalid":      {Other: "取消訂閱的連結無效。"},
+		"language.set":            {Other: "已切換為繁體中文。"},
+		"language.usage":          {Other: "回覆「語言 代碼」切換語言，支援：%[1]s"},
+		"reminder.desc.threshold": {Other: "每%[1]s走到 %[2]s 時"},
+		"reminder.desc.daily":     {Other: "每天 %[1]s"},
+		"reminder.desc.weekly":    {Other: "每%[1]s %[2]s"},
+		"reminder.period.year":    {Other: "年"},
+		"reminder.period.quarter": {Other: "季度"},
+		"reminder.period.month":   {Other: "月"},
+		"reminder.period.week":    {Other: "週"},
+		"weekday.0":               {Other: "週日"},
+		"weekday.1":               {Other: "週一"},
+		"weekday.2":               {Other: "週二"},
+		"weekday.3":               {Other: "週三"},
+		"weekday.4":               {Other: "週四"},
+		"weekday.5":               {Other: "週五"},
+		"weekday.6":               {Other: "週六"},
+	},
+	"en": {
+		"percent":                 {Other: "%[1]s%%"},
+		"year.title":              {Other: "%[1]v is %[2]s %[3]s complete"},
+		"year.heading":            {Other: "%[1]v is %[2]s complete"},
+		"quarter.name":            {Other: "Q%[2]v %[1]v"},
+		"month.name":              {Other: "%[3]s %[1]v"},
+		"week.name":               {Other: "This week"},
+		"period.title":            {Other: "%[1]s is %[2]s %[3]s complete"},
+		"period.heading":          {Other: "%[1]s is %[2]s complete"},
+		"reply.month":             {Other: "This month is %[1]s complete."},
+		"reply.week":              {Other: "This week is %[1]s complete."},
+		"lastyear.title":          {Other: "This day last year: %[1]s"},
+		"days":                    {One: "%[1]s day", Other: "%[1]s days"},
+		"day.name":                {Other: "Today"},
+		"year.name":               {Other: "%[1]v"},
+		"query.progress":          {Other: "%[1]s is %[2]s complete\n%[3]s\n%[4]s left."},
+		"query.countdown":         {Other: "%[2]s until %[1]s."},
+		"query.today":             {Other: "%[1]s is today!"},
+		"query.passed":            {Other: "%[1]s was %[2]s ago."},
+		"query.invalid":           {Other: "Invalid date range."},
+		"welcome.greeting":        {Other: "Thanks for following! Let's watch the time go by together."},
+		"reminder.added":          {Other: "Reminder #%[2]v added: %[1]s.\nReply “reminders” to list them, or “cancel reminder %[2]v” to cancel it."},
+		"reminder.item":           {Other: "#%[1]v %[2]s"},
+		"reminder.list":           {Other: "Your reminders:"},
+		"reminder.empty":          {Other: "You have no reminders."},
+		"reminder.cancelled":      {Other: "Reminder cancelled."},
+		"reminder.notfound":       {Other: "Reminder not found."},
+		"reminder.usage":          {Other: "Sorry, I don’t understand the reminder. Try:\nremind month 50%%\nremind every monday 8:00\nremind daily 21:30\nReply “reminders” to list them, or “cancel reminder ID” to cancel one.\nReminders are only delivered if you interacted with the account in the last 48 hours."},
+		"reminder.limit":          {Other: "You can set up to %[1]v reminders."},
+		"reminder.threshold":      {Other: "⏰ %[1]s is %[2]s complete\n%[3]s"},
+		"reminder.scheduled":      {Other: "⏰ Your scheduled reminder"},
+		"telegram.usage":          {Other: "/year progress of the year\n/month progress of the month\n/week progress of the week\n/until DATE countdown, e.g. /until 12-25 or /until christmas"},
+		"newsletter.footer":       {Other: "Don’t want these emails?"},
+		"newsletter.unsubscribe":  {Other: "Unsubscribe"},
+		"newsletter.confirm":      {Other: "Stop sending emails to %[1]s?"},
+		"newsletter.unsubscribed": {Other: "Unsubscribed, %[1]s won’t get emails any more."},
+		"newsletter.invalid":      {Other: "The link to unsubscribe is invalid."},
+		"language.set":            {Other: "Switched to English."},
+		"language.usage":          {Other: "Reply “language CODE” to switch the language, supported: %[1]s"},
+		"reminder.desc.threshold": {Other: "when each %[1]s hits %[2]s"},
+		"reminder.desc.daily":     {Other: "every day at %[1]s"},
+		"reminder.desc.weekly":    {Other: "every %[1]s at %[2]s"},
+		"reminder.period.year":    {Other: "year"},
+		"reminder.period.quarter": {Other: "quarter"},
+		"reminder.period.month":   {Other: "month"},
+		"reminder.period.week":    {Other: "week"},
+		"weekday.0":               {Other: "Sunday"},
+		"weekday.1":               {Other: "Monday"},
+		"weekday.2":               {Other: "Tuesday"},
+		"weekday.3":               {Other: "Wednesday"},
+		"weekday.4":               {Other: "Thursday"},
+		"weekday.5":               {Other: "Friday"},
+		"weekday.6":               {Other: "Saturday"},
+	},
+	"ja": {
+		"percent":                 {Other: "%[1]s%%"},
+		"year.title":              {Other: "%[1]v年は %[2]s %[3]s 経過しました"},
+		"year.heading":            {Other: "%[1]v年は %[2]s 経過しました"},
+		"quarter.name":            {Other: "%[1]v年第%[2]v四半期"},
+		"month.name":              {Other: "%[1]v年%[2]v月"},
+		"week.name":               {Other: "今週"},
+		"period.title":            {Other: "%[1]sは %[2]s %[3]s 経過しました"},
+		"period.heading":          {Other: "%[1]sは %[2]s 経過しました"},
+		"reply.month":             {Other: "今月は %[1]s 経過しました。"},
+		"reply.week":              {Other: "今週は %[1]s 経過しました。"},
+		"lastyear.title":          {Other: "去年の今日：%[1]s"},
+		"days":                    {Other: "%[1]s日"},
+		"day.name":                {Other: "今日"},
+		"year.name":               {Other: "%[1]v年"},
+		"query.progress":          {Other: "%[1]sは %[2]s 経過しました\n%[3]s\n残り%[4]s。"},
+		"query.countdown":         {Other: "%[1]sまであと%[2]s。"},
+		"query.today":             {Other: "今日は%[1]sです！"},
+		"query.passed":            {Other: "%[1]sから%[2]s経ちました。"},
+		"query.invalid":           {Other: "日付の範囲が無効です。"},
+		"welcome.greeting":        {Other: "フォローありがとうございます！毎日一緒に時間の流れを見守りましょう。"},
+		"reminder.added":          {Other: "リマインダー #%[2]v を追加しました：%[1]s。\n「リマインダー一覧」で確認、「リマインダー解除 %[2]v」で解除できます。"},
+		"reminder.item":           {Other: "#%[1]v %[2]s"},
+		"reminder.list":           {Other: "あなたのリマインダー："},
+		"reminder.empty":          {Other: "リマインダーはまだありません。"},
+		"reminder.cancelled":      {Other: "リマインダーを解除しました。"},
+		"reminder.notfound":       {Other: "リマインダーが見つかりません。"},
+		"reminder.usage":          {Other: "リマインダーを理解できませんでした。例：\n今月 50%% でリマインド\n月曜 8:00 にリマインド\n毎日 21:30 にリマインド\n「リマインダー一覧」で確認、「リマインダー解除 番号」で解除できます。\nリマインダーは48時間以内にやり取りがあった場合のみ届きます。"},
+		"reminder.limit":          {Other: "リマインダーは最大 %[1]v 件までです。"},
+		"reminder.threshold":      {Other: "⏰ %[1]sは %[2]s 経過しました\n%[3]s"},
+		"reminder.scheduled":      {Other: "⏰ 定時リマインダー"},
+		"telegram.usage":          {Other: "/year 今年の進捗\n/month 今月の進捗\n/week 今週の進捗\n/until 日付 カウントダウン、例：/until 12-25"},
+		"newsletter.footer":       {Other: "このメールが不要ですか？"},
+		"newsletter.unsubscribe":  {Other: "配信停止"},
+		"newsletter.confirm":      {Other: "%[1]s へのメール配信を停止しますか？"},
+		"newsletter.unsubscribed": {Other: "配信を停止しました。%[1]s にはもうメールが届きません。"},
+		"newsletter.invalid":      {Other: "配信停止のリンクが無効です。"},
+		"language.set":            {Other: "日本語に切り替えました。"},
+		"language.usage":          {Other: "「言語 コード」で言語を切り替えられます。対応：%[1]s"},
+		"reminder.desc.threshold": {Other: "毎%[1]s %[2]s に達したとき"},
+		"reminder.desc.daily":     {Other: "毎日 %[1]s"},
+		"reminder.desc.weekly":    {Other: "毎週%[1]s %[2]s"},
+		"reminder.period.year":    {Other: "年"},
+		"reminder.period.quarter": {Other: "四半期"},
+		"reminder.period.month":   {Other: "月"},
+		"reminder.period.week":    {Other: "週"},
+		"weekday.0":               {Other: "日曜日"},
+		"weekday.1":               {Other: "月曜日"},
+		"weekday.2":               {Other: "火曜日"},
+		"weekday.3":               {Other: "水曜日"},
+		"weekday.4":               {Other: "木曜日"},
+		"weekday.5":               {Other: "金曜日"},
+		"weekday.6":               {Other: "土曜日"},
+	},
+}
@@ -0,0 +1,183 @@
+// Package i18n translates messages and formats numbers for the supported locales.
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Default is the locale used when no other locale matches.
+const Default = "zh-CN"
+
+// Locales lists the supported locales.
+var Locales = []string{"zh-CN", "zh-TW", "en", "ja"}
+
+// message is a translated message, Other is used unless the plural rule of
+// the locale selects One. Both are formatted with fmt, so arguments can be
+// referenced by index, e.g. `%[2]s`.
+type message struct {
+	One   string
+	Other string
+}
+
+type numberFormat struct {
+	decimal string
+	group   string
+}
+
+var numberFormats = map[string]numberFormat{
+	"zh-CN": {".", ","},
+	"zh-TW": {".", ","},
+	"en":    {".", ","},
+	"ja":    {".", ","},
+}
+
+// Match returns the supported locale matching tag, e.g. `zh_TW` matches `zh-TW`
+// and `en-US` matches `en`. It returns Default if nothing matches.
+func Match(tag string) string {
+	tag = strings.Replace(strings.TrimSpace(tag), "_", "-", -1)
+
+	if tag == "" {
+		return Default
+	}
+
+	for _, locale := range Locales {
+		if strings.EqualFold(locale, tag) {
+			return locale
+		}
+	}
+
+	lang := strings.SplitN(tag, "-", 2)[0]
+
+	for _, locale := range Locales {
+		if strings.EqualFold(strings.SplitN(locale, "-", 2)[0], lang) {
+			return locale
+		}
+	}
+
+	return Default
+}
+
+// Supports reports whether tag matches a supported locale by Match, rather than
+// falling back to Default.
+func Supports(tag string) bool {
+	lang := strings.SplitN(strings.Replace(strings.TrimSpace(tag), "_", "-", -1), "-", 2)[0]
+
+	for _, locale := range Locales {
+		if strings.EqualFold(strings.SplitN(locale, "-", 2)[0], lang) {
+			return lang != ""
+		}
+	}
+
+	return false
+}
+
+// Fallbacks returns the locale chain of tag to look resources up, from the
+// most specific one, e.g. `zh-TW` returns [`zh-TW`, `zh`].
+func Fallbacks(tag string) []string {
+	tag = strings.Replace(tag, "_", "-", -1)
+
+	chain := []string{tag}
+
+	if i := strings.Index(tag, "-"); i > 0 {
+		chain = append(chain, tag[:i])
+	}
+
+	return chain
+}
+
+func lookup(locale, key string) (message, bool) {
+	if m, ok := catalogs[Match(locale)][key]; ok {
+		return m, true
+	}
+
+	m, ok := catalogs[Default][key]
+
+	return m, ok
+}
+
+// T translates the message of key into locale.
+func T(locale, key string, args ...interface{}) string {
+	m, ok := lookup(locale, key)
+
+	if !ok {
+		return key
+	}
+
+	return fmt.Sprintf(m.Other, args...)
+}
+
+// N translates the message of key into locale, choosing the plural form by n.
+// n is formatted as the first argument.
+func N(locale, key string, n int, args ...interface{}) string {
+	m, ok := lookup(locale, key)
+
+	if !ok {
+		return key
+	}
+
+	format := m.Other
+
+	if m.One != "" && pluralOne(Match(locale), n) {
+		format = m.One
+	}
+
+	return fmt.Sprintf(format, append([]interface{}{FormatNumber(locale, float64(n))}, args...)...)
+}
+
+// pluralOne reports whether n takes the `one` form in locale,
+// Chinese and Japanese have no plural forms.
+func pluralOne(locale string, n int) bool {
+	switch locale {
+	case "en":
+		return n == 1
+	}
+
+	return false
+}
+
+// FormatNumber formats v with the decimal and group separators of locale.
+func FormatNumber(locale string, v float64) string {
+	f, ok := numberFormats[Match(locale)]
+
+	if !ok {
+		f = numberFormats[Default]
+	}
+
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+
+	sign := ""
+
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+
+	integer, fraction := s, ""
+
+	if i := strings.Index(s, "."); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+
+	var grouped []string
+
+	for len(integer) > 3 {
+		grouped = append([]string{integer[len(integer)-3:]}, grouped...)
+		integer = integer[:len(integer)-3]
+	}
+
+	grouped = append([]string{integer}, grouped...)
+
+	s = sign + strings.Join(grouped, f.group)
+
+	if fraction != "" {
+		s += f.decimal + fraction
+	}
+
+	return s
+}
+
+// FormatPercent formats p, a value in [0, 100], as a percentage of locale.
+func FormatPercent(locale string, p float64) string {
+	return T(locale, "percent", FormatNumber(locale, p))
+}
@@ -0,0 +1,280 @@
+// Package mailer composes multipart mail and sends it over SMTP with STARTTLS.
+package mailer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a mail of a plain text part and an HTML part.
+type Message struct {
+	From    mail.Address
+	To      mail.Address
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are extra headers, e.g. List-Unsubscribe.
+	Headers map[string]string
+}
+
+// Bytes returns the message in the format of RFC 5322, as multipart/alternative
+// with quoted-printable parts.
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	w := multipart.NewWriter(&buf)
+
+	headers := map[string]string{
+		"From":         m.From.String(),
+		"To":           m.To.String(),
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         time.Now().Format(time.RFC1123Z),
+		"Message-Id":   messageId(m.From.Address),
+		"MIME-Version": "1.0",
+		"Content-Type": "multipart/alternative; boundary=" + w.Boundary(),
+	}
+
+	for key, value := range m.Headers {
+		headers[key] = value
+	}
+
+	keys := make([]string, 0, len(headers))
+
+	for key := range headers {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, headers[key])
+	}
+
+	buf.WriteString("\r\n")
+
+	parts := []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	}
+
+	for _, part := range parts {
+		pw, err := w.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+
+		if _, err := io.WriteString(qw, part.body); err != nil {
+			return nil, err
+		}
+
+		if err := qw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func messageId(from string) string {
+	domain := "localhost"
+
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+
+	return fmt.Sprintf("<%d.%d@%s>", time.Now().UnixNano(), rand.Int63(), domain)
+}
+
+// Dialer connects to the SMTP server.
+type Dialer struct {
+	Host string
+	Port int
+	// Username and Password authenticate by PLAIN if Username is set.
+	Username string
+	Password string
+	// NoTLS disables STARTTLS, it's only meant for local servers, e.g. a stub in tests.
+	NoTLS bool
+	// Timeout of the connection.
+	Timeout time.Duration
+}
+
+// Conn is a connection to the SMTP server.
+type Conn struct {
+	conn    net.Conn
+	client  *smtp.Client
+	timeout time.Duration
+}
+
+// Dial connects to the server, starts TLS unless NoTLS is set, and authenticates.
+func (d *Dialer) Dial() (*Conn, error) {
+	addr := net.JoinHostPort(d.Host, strconv.Itoa(d.Port))
+
+	conn, err := net.DialTimeout("tcp", addr, d.Timeout)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if d.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(d.Timeout))
+	}
+
+	client, err := smtp.NewClient(conn, d.Host)
+
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if !d.NoTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("the SMTP server doesn't support STARTTLS")
+		}
+
+		if err := client.StartTLS(&tls.Config{ServerName: d.Host}); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	if d.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", d.Username, d.Password, d.Host)); err != nil {
+			client.Close()
+			return nil, err
+		}
+	}
+
+	return &Conn{conn, client, d.Timeout}, nil
+}
+
+// Send sends the message, the connection can be reused for the next one.
+func (c *Conn) Send(m *Message) error {
+	data, err := m.Bytes()
+
+	if err != nil {
+		return err
+	}
+
+	if c.timeout > 0 {
+		c.conn.SetDeadline(time.Now().Add(c.timeout))
+	}
+
+	if err := c.client.Mail(m.From.Address); err != nil {
+		c.client.Reset()
+		return err
+	}
+
+	if err := c.client.Rcpt(m.To.Address); err != nil {
+		c.client.Reset()
+		return err
+	}
+
+	w, err := c.client.Data()
+
+	if err != nil {
+		c.client.Reset()
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+
+	return w.Close()
+}
+
+// Close quits the session.
+func (c *Conn) Close() error {
+	return c.client.Quit()
+}
+
+// Token returns the token of the address to unsubscribe, signed by the secret.
+func Token(secret, address string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.ToLower(address)))
+
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// CheckToken reports whether token is the token of the address.
+func CheckToken(secret, address, token string) bool {
+	return hmac.Equal([]byte(token), []byte(Token(secret, address)))
+}
+
+// ReadSubscribers reads the addresses of the file, one per line, e.g.
+// `alice@example.com` or `Bob <bob@example.com>`. Blank lines and lines
+// starting with # are ignored, and so are duplicate addresses.
+func ReadSubscribers(path string) ([]mail.Address, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var subscribers []mail.Address
+
+	seen := map[string]bool{}
+	scanner := bufio.NewScanner(f)
+
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		address, err := mail.ParseAddress(line)
+
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, n, err)
+		}
+
+		key := strings.ToLower(address.Address)
+
+		if seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		subscribers = append(subscribers, *address)
+	}
+
+	return subscribers, scanner.Err()
+}
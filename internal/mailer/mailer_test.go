@@ -0,0 +1,271 @@
+package mailer
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToken(t *testing.T) {
+	token := Token("secret", "Alice@Example.com")
+
+	if len(token) != 32 {
+		t.Errorf("len(Token()) = %d, want 32", len(token))
+	}
+
+	if Token("secret", "alice@example.com") != token {
+		t.Error("Token() depends on the case of the address")
+	}
+
+	if Token("other", "alice@example.com") == token {
+		t.Error("Token() doesn't depend on the secret")
+	}
+
+	tests := []struct {
+		secret  string
+		address string
+		token   string
+		want    bool
+	}{
+		{"secret", "alice@example.com", token, true},
+		{"secret", "ALICE@EXAMPLE.COM", token, true},
+		{"secret", "bob@example.com", token, false},
+		{"other", "alice@example.com", token, false},
+		{"secret", "alice@example.com", token[:31], false},
+		{"secret", "alice@example.com", "", false},
+	}
+
+	for i, test := range tests {
+		if got := CheckToken(test.secret, test.address, test.token); got != test.want {
+			t.Errorf("#%d: CheckToken() = %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestReadSubscribers(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mailer")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "subscribers.txt")
+	conts := "# subscribers\nalice@example.com\n\n  Bob <bob@example.com>  \nALICE@example.com\n"
+
+	if err := ioutil.WriteFile(path, []byte(conts), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	subscribers, err := ReadSubscribers(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []mail.Address{{Address: "alice@example.com"}, {Name: "Bob", Address: "bob@example.com"}}
+
+	if len(subscribers) != len(want) {
+		t.Fatalf("ReadSubscribers() = %v, want %v", subscribers, want)
+	}
+
+	for i := range want {
+		if subscribers[i] != want[i] {
+			t.Errorf("subscribers[%d] = %v, want %v", i, subscribers[i], want[i])
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte("alice@example.com\nnot an address\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ReadSubscribers(path); err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("err = %v, want an error of line 2", err)
+	}
+}
+
+// smtpStub is a local SMTP server which accepts the mails to any recipient
+// except reject, without STARTTLS.
+type smtpStub struct {
+	listener net.Listener
+	reject   string
+	mails    chan string
+}
+
+func newSMTPStub(t *testing.T, reject string) *smtpStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &smtpStub{listener, reject, make(chan string, 10)}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go s.serve(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpStub) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve(conn net.Conn) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost ESMTP stub")
+
+	for {
+		line, err := r.ReadString('\n')
+
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case s.reject != "" && strings.HasPrefix(command, "RCPT TO:") && strings.Contains(command, strings.ToUpper(s.reject)):
+			reply("550 no such user")
+		case strings.HasPrefix(command, "MAIL FROM:"), strings.HasPrefix(command, "RCPT TO:"), command == "RSET":
+			reply("250 ok")
+		case command == "DATA":
+			reply("354 go ahead")
+
+			var data strings.Builder
+
+			for {
+				line, err := r.ReadString('\n')
+
+				if err != nil {
+					return
+				}
+
+				if line == ".\r\n" {
+					break
+				}
+
+				data.WriteString(line)
+			}
+
+			s.mails <- data.String()
+			reply("250 queued")
+		case command == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+func TestDialNoTLS(t *testing.T) {
+	stub := newSMTPStub(t, "nobody@example.com")
+	defer stub.listener.Close()
+
+	d := &Dialer{Host: "127.0.0.1", Port: stub.port(), NoTLS: true}
+
+	conn, err := d.Dial()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	m := &Message{
+		From:    mail.Address{Name: "Progress", Address: "progress@example.com"},
+		To:      mail.Address{Address: "alice@example.com"},
+		Subject: "2026 年已经走过了 79%",
+		Text:    "▓▓▓▓▓▓▓░░░ 79%",
+		HTML:    "<p>▓▓▓▓▓▓▓░░░ 79%</p>",
+		Headers: map[string]string{"List-Unsubscribe": "<https://example.com/unsubscribe>"},
+	}
+
+	if err := conn.Send(m); err != nil {
+		t.Fatal(err)
+	}
+
+	data := <-stub.mails
+
+	for _, want := range []string{
+		"From: \"Progress\" <progress@example.com>\r\n",
+		"To: <alice@example.com>\r\n",
+		"Subject: =?utf-8?q?",
+		"List-Unsubscribe: <https://example.com/unsubscribe>\r\n",
+		"Content-Type: multipart/alternative; boundary=",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Type: text/html; charset=utf-8",
+		"Content-Transfer-Encoding: quoted-printable",
+	} {
+		if !strings.Contains(data, want) {
+			t.Errorf("mail doesn't contain %q:\n%s", want, data)
+		}
+	}
+
+	// The connection is reused after a rejected recipient.
+	m.To = mail.Address{Address: "nobody@example.com"}
+
+	if err := conn.Send(m); err == nil || !strings.Contains(err.Error(), "550") {
+		t.Errorf("err = %v, want 550", err)
+	}
+
+	m.To = mail.Address{Address: "bob@example.com"}
+
+	if err := conn.Send(m); err != nil {
+		t.Fatal(err)
+	}
+
+	if data := <-stub.mails; !strings.Contains(data, "To: <bob@example.com>\r\n") {
+		t.Errorf("mail isn't sent to bob:\n%s", data)
+	}
+}
+
+func TestDialRequiresSTARTTLS(t *testing.T) {
+	stub := newSMTPStub(t, "")
+	defer stub.listener.Close()
+
+	d := &Dialer{Host: "127.0.0.1", Port: stub.port()}
+
+	if _, err := d.Dial(); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("err = %v, want an error of STARTTLS", err)
+	}
+}
+
+func TestDialRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	d := &Dialer{Host: "127.0.0.1", Port: port, NoTLS: true}
+
+	if _, err := d.Dial(); err == nil {
+		t.Errorf("Dial() to the closed port %d succeeds", port)
+	}
+}
@@ -0,0 +1,184 @@
+// Package publish fans an article out to the publishers of output channels,
+// e.g. WeChat, with retries and a result record per publisher.
+package publish
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	"github.com/sqrthree/progressbar201X/internal/store"
+)
+
+// Post is an article to be published at Time.
+type Post struct {
+	Time    time.Time
+	Article *article.Article
+}
+
+// Date returns the date of the post, which identifies it among publications.
+func (p *Post) Date() string {
+	return store.DateKey(p.Time)
+}
+
+// Publisher publishes posts to an output channel.
+type Publisher interface {
+	// Name identifies the publisher in the configuration and records.
+	Name() string
+	// Prepare uploads or renders the post and returns a reference to it,
+	// which is passed to Preview and Publish.
+	Prepare(p *Post) (ref string, err error)
+	// Preview sends the prepared post to the recipient only.
+	Preview(p *Post, ref, to string) error
+	// Publish publishes the prepared post, id identifies it for Status. last is the
+	// id returned by an earlier failed attempt of the post, if any, so a publisher
+	// can resume rather than publish it twice; it may return an id with an error
+	// for the next attempt.
+	Publish(p *Post, ref, last string) (id string, err error)
+	// Status returns the status of the published post reported by the channel.
+	Status(id string) (string, error)
+}
+
+// Pipeline publishes posts by all Publishers concurrently.
+type Pipeline struct {
+	Publishers []Publisher
+	// Attempts is the maximum number of attempts of each publisher.
+	Attempts int
+	// Backoff is the delay before the first retry, it's doubled for each retry.
+	Backoff time.Duration
+	Store   *store.Store
+}
+
+// Run publishes p by the publishers and records the results. Publishers which
+// have published the post of the date are skipped, so it's safe to run again.
+func (pl *Pipeline) Run(p *Post) []store.Publication {
+	results := make([]store.Publication, len(pl.Publishers))
+
+	var wg sync.WaitGroup
+
+	for i, publisher := range pl.Publishers {
+		wg.Add(1)
+
+		go func(i int, publisher Publisher) {
+			defer wg.Done()
+
+			results[i] = pl.run(p, publisher)
+		}(i, publisher)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+func (pl *Pipeline) run(p *Post, publisher Publisher) store.Publication {
+	logger := log.WithField("publisher", publisher.Name())
+
+	previous, ok, err := pl.Store.PublicationOf(p.Date(), publisher.Name())
+
+	if err != nil {
+		logger.WithError(err).Warn("get publication")
+	}
+
+	if ok && previous.Status == store.PublicationPublished {
+		logger.Info("skip published post")
+		return previous
+	}
+
+	result := store.Publication{
+		Date:      p.Date(),
+		Publisher: publisher.Name(),
+		Ref:       previous.Ref,
+		Id:        previous.Id,
+	}
+
+	backoff := pl.Backoff
+
+	for result.Attempts < pl.attempts() {
+		if result.Attempts > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		result.Attempts++
+
+		err = pl.attempt(p, publisher, &result)
+
+		if err == nil {
+			break
+		}
+
+		logger.WithError(err).WithField("attempt", result.Attempts).Warn("publish")
+
+		if _, ok := err.(*PermanentError); ok {
+			break
+		}
+	}
+
+	result.At = time.Now()
+
+	if err != nil {
+		result.Status = store.PublicationFailed
+		result.Error = err.Error()
+		logger.WithError(err).Error("publish")
+	} else {
+		result.Status = store.PublicationPublished
+		logger.WithField("id", result.Id).Info("publish")
+	}
+
+	if err := pl.Store.AddPublication(result); err != nil {
+		logger.WithError(err).Error("record publication")
+	}
+
+	return result
+}
+
+// attempt prepares the post unless it's prepared by an earlier attempt, and publishes it.
+func (pl *Pipeline) attempt(p *Post, publisher Publisher, result *store.Publication) (err error) {
+	// A panic may happen after the post is published, so it's not retried, and the
+	// pending state recorded by the publisher before the panic is kept.
+	defer func() {
+		if v := recover(); v != nil {
+			err = Permanent(fmt.Errorf("publisher panic: %v", v))
+
+			if current, ok, e := pl.Store.PublicationOf(p.Date(), publisher.Name()); e == nil && ok && current.Status == store.PublicationPending {
+				result.Id = current.Id
+			}
+		}
+	}()
+
+	if result.Ref == "" {
+		if result.Ref, err = publisher.Prepare(p); err != nil {
+			return
+		}
+	}
+
+	result.Id, err = publisher.Publish(p, result.Ref, result.Id)
+	return
+}
+
+// PermanentError is an error of a publisher which is not retried, e.g. the post
+// may have been published and a retry may publish it twice.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent wraps err as a PermanentError.
+func Permanent(err error) error {
+	return &PermanentError{err}
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (pl *Pipeline) attempts() int {
+	if pl.Attempts < 1 {
+		return 1
+	}
+
+	return pl.Attempts
+}
@@ -0,0 +1,252 @@
+// Package query parses the text queries of followers, such as "今天", "2027",
+// "距离春节" or "my birthday 08-12", into ranges of time.
+package query
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+)
+
+// Kinds of queries.
+const (
+	// KindPeriod asks for the progress of a period.
+	KindPeriod = "period"
+	// KindCountdown asks for the days until a date.
+	KindCountdown = "countdown"
+)
+
+// Periods of queries of KindPeriod.
+const (
+	PeriodDay     = "day"
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodQuarter = "quarter"
+	PeriodYear    = "year"
+	PeriodRange   = "range"
+)
+
+var (
+	// ErrNotQuery is returned if the text is not a query at all.
+	ErrNotQuery = errors.New("not a query")
+	// ErrInvalidRange is returned if a range ends before it starts.
+	ErrInvalidRange = errors.New("invalid range")
+)
+
+// Query is a parsed query.
+type Query struct {
+	Kind string
+	// Period is the period of a query of KindPeriod.
+	Period string
+	// Label is the name given by the follower, e.g. "春节" or "my birthday".
+	Label string
+	// Range is the range of the period, or the last and the next occurrences of
+	// the target of a recurring countdown.
+	Range [2]time.Time
+	// Target is the date of a countdown.
+	Target time.Time
+	// Recurring reports whether the target of a countdown occurs every year.
+	Recurring bool
+}
+
+var periods = map[string]string{
+	"今天": PeriodDay, "今日": PeriodDay, "today": PeriodDay, "day": PeriodDay,
+	"本周": PeriodWeek, "这周": PeriodWeek, "周": PeriodWeek, "本週": PeriodWeek, "這週": PeriodWeek,
+	"今週": PeriodWeek, "week": PeriodWeek, "this week": PeriodWeek,
+	"本月": PeriodMonth, "这个月": PeriodMonth, "這個月": PeriodMonth, "月": PeriodMonth, "今月": PeriodMonth,
+	"month": PeriodMonth, "this month": PeriodMonth,
+	"季度": PeriodQuarter, "本季度": PeriodQuarter, "本季": PeriodQuarter, "这个季度": PeriodQuarter,
+	"這個季度": PeriodQuarter, "四半期": PeriodQuarter, "quarter": PeriodQuarter, "this quarter": PeriodQuarter,
+	"今年": PeriodYear, "年": PeriodYear, "year": PeriodYear, "this year": PeriodYear,
+}
+
+// holidays are the fixed dates of holidays, the Spring Festival is resolved
+// by the timeline package.
+var holidays = map[string][2]int{
+	"元旦": {1, 1}, "new year": {1, 1}, "new year's day": {1, 1},
+	"情人节": {2, 14}, "情人節": {2, 14}, "valentine's day": {2, 14},
+	"劳动节": {5, 1}, "勞動節": {5, 1}, "五一": {5, 1}, "labor day": {5, 1},
+	"国庆": {10, 1}, "国庆节": {10, 1}, "國慶": {10, 1}, "國慶節": {10, 1}, "十一": {10, 1},
+	"圣诞": {12, 25}, "圣诞节": {12, 25}, "聖誕": {12, 25}, "聖誕節": {12, 25}, "christmas": {12, 25},
+}
+
+var springFestival = map[string]bool{
+	"春节": true, "春節": true, "过年": true, "過年": true,
+	"spring festival": true, "chinese new year": true, "lunar new year": true,
+}
+
+var (
+	yearPattern      = regexp.MustCompile(`^(\d{4})\s*年?$`)
+	datePattern      = `(?:\d{4}[-/.])?\d{1,2}[-/.]\d{1,2}`
+	rangePattern     = regexp.MustCompile(`^(` + datePattern + `)\s*(?:~|～|至|到|to|\s-\s)\s*(` + datePattern + `)$`)
+	labelDatePattern = regexp.MustCompile(`^(.*?)\s*(` + datePattern + `)$`)
+	countdownPrefix  = regexp.MustCompile(`^(?:距离|距離|离|離|倒计时|倒計時|countdown to|countdown|days until|until)\s*`)
+	countdownSuffix  = regexp.MustCompile(`\s*(?:还有多久|還有多久|还有几天|還有幾天|まで)$`)
+)
+
+// Parse parses text sent at now, ErrNotQuery is returned if it's not a query.
+func Parse(text string, now time.Time) (*Query, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	text = strings.TrimRight(text, "?？!！。.")
+
+	if period, ok := periods[text]; ok {
+		return NewPeriod(period, now), nil
+	}
+
+	if m := yearPattern.FindStringSubmatch(text); m != nil {
+		year, _ := strconv.Atoi(m[1])
+
+		return &Query{Kind: KindPeriod, Period: PeriodYear, Label: m[1], Range: timeline.Year(year)}, nil
+	}
+
+	if m := rangePattern.FindStringSubmatch(text); m != nil {
+		return newRange(m[1], m[2], now)
+	}
+
+	target := countdownSuffix.ReplaceAllString(countdownPrefix.ReplaceAllString(text, ""), "")
+
+	if target == "" {
+		return nil, ErrNotQuery
+	}
+
+	if springFestival[target] {
+		d, err := timeline.SpringFestival(now)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return newCountdown(target, d[1], d, true), nil
+	}
+
+	if date, ok := holidays[target]; ok {
+		d := timeline.Annual(now, time.Month(date[0]), date[1])
+
+		return newCountdown(target, d[1], d, true), nil
+	}
+
+	// A date with an optional label, e.g. "2027-01-01" or "my birthday 08-12".
+	if m := labelDatePattern.FindStringSubmatch(target); m != nil {
+		label := m[1]
+
+		if label == "" {
+			label = m[2]
+		}
+
+		year, month, day, hasYear, err := parseDate(m[2])
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !hasYear {
+			d := timeline.Annual(now, month, day)
+
+			return newCountdown(label, d[1], d, true), nil
+		}
+
+		return newCountdown(label, time.Date(year, month, day, 0, 0, 0, 0, time.UTC), [2]time.Time{}, false), nil
+	}
+
+	return nil, ErrNotQuery
+}
+
+// NewPeriod returns the query of the named period containing now.
+func NewPeriod(period string, now time.Time) *Query {
+	q := &Query{Kind: KindPeriod, Period: period}
+
+	switch period {
+	case PeriodDay:
+		q.Range = timeline.Day(now)
+	case PeriodWeek:
+		q.Range = timeline.Week(now)
+	case PeriodMonth:
+		q.Range = timeline.Month(now)
+	case PeriodQuarter:
+		q.Range = timeline.Quarter(now)
+	case PeriodYear:
+		q.Range = timeline.Year(now.Year())
+	}
+
+	return q
+}
+
+// newRange returns the query of the range from start to end, both days are included.
+func newRange(start, end string, now time.Time) (*Query, error) {
+	var d [2]time.Time
+
+	for i, s := range []string{start, end} {
+		year, month, day, hasYear, err := parseDate(s)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !hasYear {
+			year = now.Year()
+
+			if day > daysIn(year, month) {
+				return nil, errors.New("invalid date " + s)
+			}
+		}
+
+		d[i] = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	d[1] = d[1].AddDate(0, 0, 1)
+
+	if !d[1].After(d[0]) {
+		return nil, ErrInvalidRange
+	}
+
+	label := d[0].Format("2006-01-02") + " ~ " + d[1].AddDate(0, 0, -1).Format("2006-01-02")
+
+	return &Query{Kind: KindPeriod, Period: PeriodRange, Label: label, Range: d}, nil
+}
+
+func newCountdown(label string, target time.Time, d [2]time.Time, recurring bool) *Query {
+	return &Query{Kind: KindCountdown, Label: label, Target: target, Range: d, Recurring: recurring}
+}
+
+// parseDate parses a date of year-month-day or month-day, separated by "-", "/" or ".".
+func parseDate(s string) (year int, month time.Month, day int, hasYear bool, err error) {
+	parts := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '-' || r == '/' || r == '.'
+	})
+
+	nums := make([]int, len(parts))
+
+	for i, part := range parts {
+		if nums[i], err = strconv.Atoi(part); err != nil {
+			return
+		}
+	}
+
+	if len(nums) == 3 {
+		year, nums, hasYear = nums[0], nums[1:], true
+	}
+
+	// Without the year, the day is checked in a leap year to allow 02-29.
+	y := 2000
+
+	if hasYear {
+		y = year
+	}
+
+	if len(nums) != 2 || nums[0] < 1 || nums[0] > 12 || nums[1] < 1 || nums[1] > daysIn(y, time.Month(nums[0])) {
+		err = errors.New("invalid date " + s)
+		return
+	}
+
+	month, day = time.Month(nums[0]), nums[1]
+	return
+}
+
+// daysIn returns the number of days of the month of the year.
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
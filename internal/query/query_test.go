@@ -0,0 +1,87 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParse(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text      string
+		kind      string
+		period    string
+		label     string
+		target    time.Time
+		start     time.Time
+		end       time.Time
+		recurring bool
+	}{
+		{text: "今天", kind: KindPeriod, period: PeriodDay, start: date(2026, 10, 19), end: date(2026, 10, 20)},
+		{text: "This Month?", kind: KindPeriod, period: PeriodMonth, start: date(2026, 10, 1), end: date(2026, 11, 1)},
+		{text: "2027年", kind: KindPeriod, period: PeriodYear, label: "2027", start: date(2027, 1, 1), end: date(2028, 1, 1)},
+		{text: "10-01 ~ 10-31", kind: KindPeriod, period: PeriodRange, label: "2026-10-01 ~ 2026-10-31", start: date(2026, 10, 1), end: date(2026, 11, 1)},
+		{text: "2028-02-01 到 2028-02-29", kind: KindPeriod, period: PeriodRange, label: "2028-02-01 ~ 2028-02-29", start: date(2028, 2, 1), end: date(2028, 3, 1)},
+		{text: "距离春节", kind: KindCountdown, label: "春节", target: date(2027, 2, 6), start: date(2026, 2, 17), end: date(2027, 2, 6), recurring: true},
+		{text: "christmas!", kind: KindCountdown, label: "christmas", target: date(2026, 12, 25), start: date(2025, 12, 25), end: date(2026, 12, 25), recurring: true},
+		{text: "my birthday 08-12", kind: KindCountdown, label: "my birthday", target: date(2027, 8, 12), start: date(2026, 8, 12), end: date(2027, 8, 12), recurring: true},
+		{text: "2027-01-01", kind: KindCountdown, label: "2027-01-01", target: date(2027, 1, 1)},
+		{text: "2028/02/29", kind: KindCountdown, label: "2028/02/29", target: date(2028, 2, 29)},
+		// 02-29 falls on 02-28 in common years.
+		{text: "02-29", kind: KindCountdown, label: "02-29", target: date(2027, 2, 28), start: date(2026, 2, 28), end: date(2027, 2, 28), recurring: true},
+	}
+
+	for _, test := range tests {
+		q, err := Parse(test.text, now)
+
+		if err != nil {
+			t.Errorf("Parse(%q) returns %v", test.text, err)
+			continue
+		}
+
+		if q.Kind != test.kind || q.Period != test.period || q.Label != test.label || q.Recurring != test.recurring {
+			t.Errorf("Parse(%q) = %+v", test.text, q)
+		}
+
+		if !q.Target.Equal(test.target) || !q.Range[0].Equal(test.start) || !q.Range[1].Equal(test.end) {
+			t.Errorf("Parse(%q) = %v, %v, want %v, %v", test.text, q.Target, q.Range, test.target, [2]time.Time{test.start, test.end})
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		text string
+		err  error
+	}{
+		{"", ErrNotQuery},
+		{"hello", ErrNotQuery},
+		{"距离", ErrNotQuery},
+		{"10-31 ~ 10-01", ErrInvalidRange},
+		{"13-01", nil},
+		{"04-31", nil},
+		{"02-30", nil},
+		{"2027-02-29", nil},
+		{"02-29 ~ 03-01", nil},
+	}
+
+	for _, test := range tests {
+		q, err := Parse(test.text, now)
+
+		if err == nil {
+			t.Errorf("Parse(%q) = %+v, want an error", test.text, q)
+			continue
+		}
+
+		if test.err != nil && err != test.err {
+			t.Errorf("Parse(%q) returns %v, want %v", test.text, err, test.err)
+		}
+	}
+}
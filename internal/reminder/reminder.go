@@ -0,0 +1,202 @@
+// Package reminder parses the reminder commands of followers and decides when
+// reminders are due. Reminders are delivered by customer service messages.
+package reminder
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sqrthree/progressbar201X/internal/query"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/timeline"
+)
+
+// Kinds of reminders.
+const (
+	// KindThreshold reminds once a period when its progress reaches the percent.
+	KindThreshold = "threshold"
+	// KindDaily reminds every day at the time.
+	KindDaily = "daily"
+	// KindWeekly reminds every week on the weekday at the time.
+	KindWeekly = "weekly"
+)
+
+// Actions of commands.
+const (
+	ActionAdd    = "add"
+	ActionList   = "list"
+	ActionCancel = "cancel"
+)
+
+// MaxReminders is the maximum number of reminders of a follower.
+const MaxReminders = 10
+
+// Window is how long a scheduled reminder is due after its time, in case a tick is missed.
+const Window = time.Hour
+
+var (
+	// ErrNotCommand is returned if the text is not a reminder command.
+	ErrNotCommand = errors.New("not a reminder command")
+	// ErrInvalid is returned if the text is a reminder command but can't be understood.
+	ErrInvalid = errors.New("invalid reminder command")
+)
+
+// Command is a parsed reminder command.
+type Command struct {
+	Action string
+	// Reminder is the reminder to add.
+	Reminder store.Reminder
+	// Id is the id of the reminder to cancel, 0 cancels all.
+	Id int
+}
+
+var (
+	listPattern    = regexp.MustCompile(`^(?:提醒列表|我的提醒|查看提醒|reminders|list reminders|my reminders|リマインダー一覧)$`)
+	cancelPattern  = regexp.MustCompile(`^(?:取消提醒|删除提醒|刪除提醒|cancel reminders?|remove reminders?|リマインダー解除)\s*(\d+|全部|所有|all|すべて)?$`)
+	percentPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)\s*[%％]`)
+	clockPattern   = regexp.MustCompile(`(\d{1,2})\s*[:：]\s*(\d{2})`)
+	hourPattern    = regexp.MustCompile(`(\d{1,2})\s*[点點時]`)
+)
+
+// addWords mark a text as a command to add a reminder.
+var addWords = []string{"提醒", "remind", "リマインド"}
+
+// periodWords are searched in order, so that "季度" isn't taken as "年".
+var periodWords = [][2]string{
+	{"季度", query.PeriodQuarter}, {"四半期", query.PeriodQuarter}, {"quarter", query.PeriodQuarter},
+	{"月", query.PeriodMonth}, {"month", query.PeriodMonth},
+	{"周", query.PeriodWeek}, {"週", query.PeriodWeek}, {"week", query.PeriodWeek},
+	{"年", query.PeriodYear}, {"year", query.PeriodYear},
+}
+
+// weekdayWords are the names of weekdays, e.g. "周一", "星期天", "月曜" and "monday".
+var weekdayWords = make(map[string]time.Weekday)
+
+func init() {
+	chinese := []string{"日", "一", "二", "三", "四", "五", "六"}
+	japanese := []string{"日曜", "月曜", "火曜", "水曜", "木曜", "金曜", "土曜"}
+
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		for _, prefix := range []string{"周", "週", "星期", "礼拜", "禮拜"} {
+			weekdayWords[prefix+chinese[day]] = day
+		}
+
+		weekdayWords[japanese[day]] = day
+		weekdayWords[strings.ToLower(day.String())] = day
+	}
+
+	for _, prefix := range []string{"周", "週", "星期", "礼拜", "禮拜"} {
+		weekdayWords[prefix+"天"] = time.Sunday
+	}
+}
+
+// Parse parses the text of a follower, ErrNotCommand is returned if it's not a
+// reminder command.
+func Parse(text string) (*Command, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	text = strings.TrimRight(text, "?？!！。.")
+
+	if listPattern.MatchString(text) {
+		return &Command{Action: ActionList}, nil
+	}
+
+	if m := cancelPattern.FindStringSubmatch(text); m != nil {
+		if m[1] == "" {
+			return nil, ErrInvalid
+		}
+
+		id, _ := strconv.Atoi(m[1])
+
+		return &Command{Action: ActionCancel, Id: id}, nil
+	}
+
+	if !containsAny(text, addWords) {
+		return nil, ErrNotCommand
+	}
+
+	if m := percentPattern.FindStringSubmatch(text); m != nil {
+		percent, _ := strconv.ParseFloat(m[1], 64)
+
+		for _, word := range periodWords {
+			if strings.Contains(text, word[0]) && percent > 0 && percent <= 100 {
+				return add(store.Reminder{Kind: KindThreshold, Period: word[1], Percent: percent}), nil
+			}
+		}
+
+		return nil, ErrInvalid
+	}
+
+	hour, minute, ok := parseClock(text)
+
+	if !ok {
+		return nil, ErrInvalid
+	}
+
+	for word, weekday := range weekdayWords {
+		if strings.Contains(text, word) {
+			return add(store.Reminder{Kind: KindWeekly, Weekday: weekday, Hour: hour, Minute: minute}), nil
+		}
+	}
+
+	// A time without a weekday is daily, with or without the word of daily.
+	return add(store.Reminder{Kind: KindDaily, Hour: hour, Minute: minute}), nil
+}
+
+func add(r store.Reminder) *Command {
+	return &Command{Action: ActionAdd, Reminder: r}
+}
+
+func containsAny(text string, words []string) bool {
+	for _, word := range words {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseClock finds a time of "8:00" or "8点" in text.
+func parseClock(text string) (hour, minute int, ok bool) {
+	if m := clockPattern.FindStringSubmatch(text); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+		minute, _ = strconv.Atoi(m[2])
+	} else if m := hourPattern.FindStringSubmatch(text); m != nil {
+		hour, _ = strconv.Atoi(m[1])
+	} else {
+		return 0, 0, false
+	}
+
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+
+	return hour, minute, true
+}
+
+// Due reports whether r is due at now, key identifies the occurrence, which is
+// delivered only once. Scheduled reminders are due within `Window` after the time.
+func Due(r store.Reminder, now time.Time) (key string, due bool) {
+	switch r.Kind {
+	case KindThreshold:
+		d := query.NewPeriod(r.Period, now).Range
+		key = store.DateKey(d[0])
+
+		return key, r.LastKey != key && timeline.Progress(now, d)*100 >= r.Percent
+	case KindDaily, KindWeekly:
+		if r.Kind == KindWeekly && now.Weekday() != r.Weekday {
+			return "", false
+		}
+
+		today := timeline.Day(now)[0]
+		at := today.Add(time.Duration(r.Hour)*time.Hour + time.Duration(r.Minute)*time.Minute)
+		key = store.DateKey(today)
+
+		return key, r.LastKey != key && !now.Before(at) && now.Sub(at) < Window
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	deliveryBucket      = "deliveries"
+	deliveryMsgIdBucket = "delivery_msgids"
+)
+
+// Statuses of deliveries.
+const (
+	// DeliveryPending means the message is accepted, the result is reported later.
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "success"
+	DeliveryFailed    = "failed"
+)
+
+// Delivery is the record of a message sent to a recipient through a channel,
+// e.g. a template message to a follower.
+type Delivery struct {
+	Date      string `json:"date"`
+	Channel   string `json:"channel"`
+	Recipient string `json:"recipient"`
+	MsgId     int64  `json:"msg_id,omitempty"`
+	Status    string `json:"status"`
+	// Error is the error of a failed delivery, or the status reported by WeChat.
+	Error string `json:"error,omitempty"`
+	// Attempts is the number of attempts of a delivery retried by the sender.
+	Attempts int       `json:"attempts,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+func deliveryKey(date, channel, recipient string) string {
+	return date + "#" + channel + "#" + recipient
+}
+
+// AddDelivery records d, it replaces the earlier delivery to the recipient
+// through the channel of the same date.
+func (s *Store) AddDelivery(d Delivery) error {
+	return s.AddDeliveries(d)
+}
+
+// AddDeliveries records the deliveries like AddDelivery, the file is saved once.
+func (s *Store) AddDeliveries(deliveries ...Delivery) error {
+	var b Batch
+
+	for _, d := range deliveries {
+		key := deliveryKey(d.Date, d.Channel, d.Recipient)
+
+		if err := b.Put(deliveryBucket, key, d); err != nil {
+			return err
+		}
+
+		if d.MsgId != 0 {
+			if err := b.Put(deliveryMsgIdBucket, strconv.FormatInt(d.MsgId, 10), key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.Write(&b)
+}
+
+// DeliveryOf returns the delivery to the recipient through the channel of the date,
+// ok is false if there isn't any.
+func (s *Store) DeliveryOf(date, channel, recipient string) (d Delivery, ok bool, err error) {
+	ok, err = s.Get(deliveryBucket, deliveryKey(date, channel, recipient), &d)
+	return
+}
+
+// UpdateDeliveryStatus updates the status of the delivery of msgId,
+// ok is false if the delivery is unknown.
+func (s *Store) UpdateDeliveryStatus(msgId int64, status, reason string) (ok bool, err error) {
+	var key string
+
+	if ok, err = s.Get(deliveryMsgIdBucket, strconv.FormatInt(msgId, 10), &key); !ok || err != nil {
+		return
+	}
+
+	var d Delivery
+
+	if ok, err = s.Get(deliveryBucket, key, &d); !ok || err != nil {
+		return
+	}
+
+	d.Status = status
+	d.Error = reason
+
+	return true, s.Put(deliveryBucket, key, d)
+}
+
+// DeliveriesOf returns the deliveries of the date.
+func (s *Store) DeliveriesOf(date string) ([]Delivery, error) {
+	var deliveries []Delivery
+
+	for _, key := range s.Keys(deliveryBucket) {
+		if !strings.HasPrefix(key, date+"#") {
+			continue
+		}
+
+		var d Delivery
+
+		if _, err := s.Get(deliveryBucket, key, &d); err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Expiring is a bucket of the store whose values expire,
+// e.g. to share the idempotency cache of callbacks.
+type Expiring struct {
+	store  *Store
+	bucket string
+}
+
+type expiringValue struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// Expiring returns the bucket of the store whose values expire.
+func (s *Store) Expiring(bucket string) *Expiring {
+	return &Expiring{s, bucket}
+}
+
+// Get returns the value of key, ok is false if key is not set or expired.
+func (e *Expiring) Get(key string) (value []byte, ok bool, err error) {
+	var v expiringValue
+
+	ok, err = e.store.Get(e.bucket, key, &v)
+
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	if time.Now().After(v.Expires) {
+		return nil, false, nil
+	}
+
+	return v.Value, true, nil
+}
+
+// Set sets the value of key which expires after ttl, expired values of the
+// bucket are removed as well. The file is saved later, see `Store.PutLater`.
+func (e *Expiring) Set(key string, value []byte, ttl time.Duration) error {
+	raw, err := json.Marshal(expiringValue{value, time.Now().Add(ttl)})
+
+	if err != nil {
+		return err
+	}
+
+	s := e.store
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.buckets[e.bucket] == nil {
+		s.buckets[e.bucket] = make(map[string]json.RawMessage)
+	}
+
+	now := time.Now()
+
+	for k, raw := range s.buckets[e.bucket] {
+		var v expiringValue
+
+		if err := json.Unmarshal(raw, &v); err != nil || now.After(v.Expires) {
+			delete(s.buckets[e.bucket], k)
+		}
+	}
+
+	s.buckets[e.bucket][key] = raw
+	s.saveLater()
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	followerBucket          = "followers"
+	subscriptionEventBucket = "subscription_events"
+)
+
+// Follower is the subscription record of a follower.
+type Follower struct {
+	OpenId     string `json:"open_id"`
+	Subscribed bool   `json:"subscribed"`
+	// Scene is the scene of the QR code the follower subscribed by last time.
+	Scene          string    `json:"scene,omitempty"`
+	SubscribedAt   time.Time `json:"subscribed_at"`
+	UnsubscribedAt time.Time `json:"unsubscribed_at"`
+	// Subscriptions counts the times of subscribing, more than 1 means resubscribed.
+	Subscriptions int `json:"subscriptions"`
+}
+
+// SubscriptionEvent is a change of subscription.
+type SubscriptionEvent struct {
+	OpenId     string    `json:"open_id"`
+	Subscribed bool      `json:"subscribed"`
+	Scene      string    `json:"scene,omitempty"`
+	At         time.Time `json:"at"`
+}
+
+// Subscribe records that the follower subscribes at t by scene.
+func (s *Store) Subscribe(openId, scene string, t time.Time) error {
+	defer s.lockFollower(openId)()
+
+	f, _, err := s.FollowerOf(openId)
+
+	if err != nil {
+		return err
+	}
+
+	f.OpenId = openId
+	f.Subscribed = true
+	f.Scene = scene
+	f.SubscribedAt = t
+	f.Subscriptions++
+
+	if err := s.Put(followerBucket, openId, f); err != nil {
+		return err
+	}
+
+	return s.addSubscriptionEvent(SubscriptionEvent{openId, true, scene, t})
+}
+
+// Unsubscribe records that the follower unsubscribes at t.
+func (s *Store) Unsubscribe(openId string, t time.Time) error {
+	defer s.lockFollower(openId)()
+
+	f, _, err := s.FollowerOf(openId)
+
+	if err != nil {
+		return err
+	}
+
+	f.OpenId = openId
+	f.Subscribed = false
+	f.UnsubscribedAt = t
+
+	if err := s.Put(followerBucket, openId, f); err != nil {
+		return err
+	}
+
+	return s.addSubscriptionEvent(SubscriptionEvent{OpenId: openId, At: t})
+}
+
+// lockFollower locks the record of the follower, and returns the function to unlock it.
+func (s *Store) lockFollower(openId string) func() {
+	v, _ := s.followerLocks.LoadOrStore(openId, &sync.Mutex{})
+	mutex := v.(*sync.Mutex)
+
+	mutex.Lock()
+	return mutex.Unlock
+}
+
+// FollowerOf returns the record of the follower, ok is false if there isn't any.
+func (s *Store) FollowerOf(openId string) (f Follower, ok bool, err error) {
+	ok, err = s.Get(followerBucket, openId, &f)
+	return
+}
+
+// Followers returns the records of all followers, including the unsubscribed ones.
+func (s *Store) Followers() ([]Follower, error) {
+	keys := s.Keys(followerBucket)
+	followers := make([]Follower, 0, len(keys))
+
+	for _, key := range keys {
+		var f Follower
+
+		if _, err := s.Get(followerBucket, key, &f); err != nil {
+			return nil, err
+		}
+
+		followers = append(followers, f)
+	}
+
+	return followers, nil
+}
+
+// SubscriptionEvents returns the changes of subscription in time order.
+func (s *Store) SubscriptionEvents() ([]SubscriptionEvent, error) {
+	keys := s.Keys(subscriptionEventBucket)
+	events := make([]SubscriptionEvent, 0, len(keys))
+
+	for _, key := range keys {
+		var e SubscriptionEvent
+
+		if _, err := s.Get(subscriptionEventBucket, key, &e); err != nil {
+			return nil, err
+		}
+
+		events = append(events, e)
+	}
+
+	return events, nil
+}
+
+func (s *Store) addSubscriptionEvent(e SubscriptionEvent) error {
+	// Keys are sorted as strings, so the time is formatted in a fixed width.
+	key := e.At.UTC().Format("20060102T150405.000000000") + "#" + e.OpenId
+
+	return s.Put(subscriptionEventBucket, key, e)
+}
@@ -0,0 +1,51 @@
+package store
+
+import "time"
+
+const historyBucket = "history"
+
+// History is the record of a broadcast article.
+type History struct {
+	Date        string    `json:"date"`
+	Year        int       `json:"year"`
+	Progress    float64   `json:"progress"`
+	Title       string    `json:"title"`
+	Digest      string    `json:"digest"`
+	Content     string    `json:"content"`
+	MediaId     string    `json:"media_id"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// DateKey formats t as the key of histories.
+func DateKey(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// AddHistory records h, a later broadcast of the same date replaces the earlier one.
+func (s *Store) AddHistory(h History) error {
+	return s.Put(historyBucket, h.Date, h)
+}
+
+// HistoryOf returns the history of the date, ok is false if there isn't any.
+func (s *Store) HistoryOf(date string) (h History, ok bool, err error) {
+	ok, err = s.Get(historyBucket, date, &h)
+	return
+}
+
+// Histories returns all histories, the latest first.
+func (s *Store) Histories() ([]History, error) {
+	keys := s.Keys(historyBucket)
+	histories := make([]History, 0, len(keys))
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		var h History
+
+		if _, err := s.Get(historyBucket, keys[i], &h); err != nil {
+			return nil, err
+		}
+
+		histories = append(histories, h)
+	}
+
+	return histories, nil
+}
@@ -0,0 +1,30 @@
+package store
+
+import "time"
+
+const interactionBucket = "interactions"
+
+// SetLastInteraction records the time the follower interacted with the account last,
+// which opens the window of customer service messages. It's recorded on every
+// message, so the file is saved later, see `PutLater`.
+func (s *Store) SetLastInteraction(openId string, t time.Time) error {
+	return s.PutLater(interactionBucket, openId, t)
+}
+
+// LastInteractionOf returns the time the follower interacted with the account last,
+// it's zero if unknown.
+func (s *Store) LastInteractionOf(openId string) (t time.Time, err error) {
+	_, err = s.Get(interactionBucket, openId, &t)
+	return
+}
+
+// InteractedWithin reports whether the follower interacted with the account within d.
+func (s *Store) InteractedWithin(openId string, d time.Duration) (bool, error) {
+	t, err := s.LastInteractionOf(openId)
+
+	if err != nil {
+		return false, err
+	}
+
+	return time.Since(t) < d, nil
+}
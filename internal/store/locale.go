@@ -0,0 +1,14 @@
+package store
+
+const localeBucket = "locales"
+
+// SetLocale sets the locale of the follower.
+func (s *Store) SetLocale(openId, locale string) error {
+	return s.Put(localeBucket, openId, locale)
+}
+
+// LocaleOf returns the locale of the follower, it's empty if not set.
+func (s *Store) LocaleOf(openId string) (locale string, err error) {
+	_, err = s.Get(localeBucket, openId, &locale)
+	return
+}
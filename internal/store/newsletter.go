@@ -0,0 +1,20 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+const unsubscribedBucket = "newsletter_unsubscribed"
+
+// UnsubscribeNewsletter records that the address has unsubscribed from the newsletter at t.
+func (s *Store) UnsubscribeNewsletter(address string, t time.Time) error {
+	return s.Put(unsubscribedBucket, strings.ToLower(address), t)
+}
+
+// NewsletterUnsubscribed reports whether the address has unsubscribed from the newsletter.
+func (s *Store) NewsletterUnsubscribed(address string) (bool, error) {
+	var t time.Time
+
+	return s.Get(unsubscribedBucket, strings.ToLower(address), &t)
+}
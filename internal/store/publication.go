@@ -0,0 +1,64 @@
+package store
+
+import (
+	"strings"
+	"time"
+)
+
+const publicationBucket = "publications"
+
+// Statuses of publications.
+const (
+	PublicationPublished = "published"
+	PublicationFailed    = "failed"
+	// PublicationPending is recorded by publishers before an operation that mustn't
+	// be repeated, e.g. a mass send, it's replaced by the result.
+	PublicationPending = "pending"
+)
+
+// Publication is the result of publishing the article of a date by a publisher.
+type Publication struct {
+	Date      string `json:"date"`
+	Publisher string `json:"publisher"`
+	// Ref is the prepared post, e.g. the media id of WeChat.
+	Ref string `json:"ref,omitempty"`
+	// Id identifies the published post to query its status.
+	Id       string    `json:"id,omitempty"`
+	Status   string    `json:"status"`
+	Attempts int       `json:"attempts"`
+	Error    string    `json:"error,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// AddPublication records p, it replaces the earlier one of the same date and publisher.
+func (s *Store) AddPublication(p Publication) error {
+	return s.Put(publicationBucket, p.Date+"#"+p.Publisher, p)
+}
+
+// PublicationOf returns the publication of the date by the publisher,
+// ok is false if there isn't any.
+func (s *Store) PublicationOf(date, publisher string) (p Publication, ok bool, err error) {
+	ok, err = s.Get(publicationBucket, date+"#"+publisher, &p)
+	return
+}
+
+// PublicationsOf returns the publications of the date.
+func (s *Store) PublicationsOf(date string) ([]Publication, error) {
+	var publications []Publication
+
+	for _, key := range s.Keys(publicationBucket) {
+		if !strings.HasPrefix(key, date+"#") {
+			continue
+		}
+
+		var p Publication
+
+		if _, err := s.Get(publicationBucket, key, &p); err != nil {
+			return nil, err
+		}
+
+		publications = append(publications, p)
+	}
+
+	return publications, nil
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"sync"
+	"time"
+)
+
+const reminderBucket = "reminders"
+
+// Reminder is a reminder registered by a follower, see the reminder package.
+type Reminder struct {
+	// Id identifies the reminder among the ones of the follower.
+	Id   int    `json:"id"`
+	Kind string `json:"kind"`
+	// Period and Percent are the threshold of a threshold reminder.
+	Period  string  `json:"period,omitempty"`
+	Percent float64 `json:"percent,omitempty"`
+	// Weekday, Hour and Minute are the time of a scheduled reminder.
+	Weekday   time.Weekday `json:"weekday,omitempty"`
+	Hour      int          `json:"hour,omitempty"`
+	Minute    int          `json:"minute,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	// LastKey identifies the last occurrence handled, so it's delivered only once.
+	LastKey string `json:"last_key,omitempty"`
+}
+
+// RemindersOf returns the reminders of the follower.
+func (s *Store) RemindersOf(openId string) (reminders []Reminder, err error) {
+	_, err = s.Get(reminderBucket, openId, &reminders)
+	return
+}
+
+// SetReminders replaces the reminders of the follower.
+func (s *Store) SetReminders(openId string, reminders []Reminder) error {
+	if len(reminders) == 0 {
+		return s.Delete(reminderBucket, openId)
+	}
+
+	return s.Put(reminderBucket, openId, reminders)
+}
+
+// UpdateReminders replaces the reminders of the follower by the result of update,
+// which is called with the current ones; nothing is saved unless changed is true.
+// Updates of the same follower are serialized, so they never overwrite each other.
+func (s *Store) UpdateReminders(openId string, update func(reminders []Reminder) (updated []Reminder, changed bool)) error {
+	v, _ := s.reminderLocks.LoadOrStore(openId, &sync.Mutex{})
+	mutex := v.(*sync.Mutex)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	reminders, err := s.RemindersOf(openId)
+
+	if err != nil {
+		return err
+	}
+
+	reminders, changed := update(reminders)
+
+	if !changed {
+		return nil
+	}
+
+	return s.SetReminders(openId, reminders)
+}
+
+// ReminderFollowers returns the OpenIDs of the followers with reminders.
+func (s *Store) ReminderFollowers() []string {
+	return s.Keys(reminderBucket)
+}
@@ -0,0 +1,275 @@
+// Package store persists the state of the application in a JSON file.
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+)
+
+// Store is a key-value store grouped by buckets, it's saved to the file at path
+// after every change, except the frequent ones which are saved together, see
+// `PutLater`, and the changes of a `Batch`, which are saved at once.
+type Store struct {
+	path    string
+	mutex   sync.Mutex
+	buckets map[string]map[string]json.RawMessage
+	// dirty is true if changes are waiting for the save scheduled by saveLater.
+	dirty bool
+	// reminderLocks serializes the updates of the reminders of each follower.
+	reminderLocks sync.Map
+	// followerLocks serializes the updates of the record of each follower.
+	followerLocks sync.Map
+}
+
+var (
+	defaultStore *Store
+	defaultOnce  sync.Once
+)
+
+// Default returns the store shared by the application, which is opened from
+// `Config.Store.Path`. It falls back to an in-memory store if the file can't be opened.
+func Default() *Store {
+	defaultOnce.Do(func() {
+		s, err := Open(Config.Store.Path)
+
+		if err != nil {
+			log.WithError(err).Error("open store, use in-memory store instead")
+
+			s, _ = Open("")
+		}
+
+		defaultStore = s
+	})
+
+	return defaultStore
+}
+
+// Open opens the store saved at path, the store is kept in memory only
+// if path is empty.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		buckets: make(map[string]map[string]json.RawMessage),
+	}
+
+	if path == "" {
+		return s, nil
+	}
+
+	conts, err := ioutil.ReadFile(path)
+
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(conts, &s.buckets); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Get decodes the value of key in bucket into v, and reports whether the key exists.
+func (s *Store) Get(bucket, key string, v interface{}) (bool, error) {
+	s.mutex.Lock()
+	raw, ok := s.buckets[bucket][key]
+	s.mutex.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(raw, v)
+}
+
+// Put sets the value of key in bucket to v.
+func (s *Store) Put(bucket, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]json.RawMessage)
+	}
+
+	s.buckets[bucket][key] = raw
+
+	return s.save()
+}
+
+// PutLater sets the value of key in bucket to v like Put, but the file is saved
+// within saveDelay, together with the other changes in the meantime. It's meant for
+// frequent changes which can be lost on crashes, e.g. the time of interactions.
+func (s *Store) PutLater(bucket, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.buckets[bucket] == nil {
+		s.buckets[bucket] = make(map[string]json.RawMessage)
+	}
+
+	s.buckets[bucket][key] = raw
+	s.saveLater()
+
+	return nil
+}
+
+// Batch is a set of changes which are written by `Store.Write` at once.
+type Batch struct {
+	entries []batchEntry
+}
+
+type batchEntry struct {
+	bucket, key string
+	raw         json.RawMessage
+}
+
+// Put sets the value of key in bucket to v when the batch is written.
+func (b *Batch) Put(bucket, key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	b.entries = append(b.entries, batchEntry{bucket, key, raw})
+
+	return nil
+}
+
+// Len returns the number of changes of the batch.
+func (b *Batch) Len() int {
+	return len(b.entries)
+}
+
+// Write applies the changes of b and saves the file once, b is empty afterwards.
+func (s *Store) Write(b *Batch) error {
+	if b.Len() == 0 {
+		return nil
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, e := range b.entries {
+		if s.buckets[e.bucket] == nil {
+			s.buckets[e.bucket] = make(map[string]json.RawMessage)
+		}
+
+		s.buckets[e.bucket][e.key] = e.raw
+	}
+
+	b.entries = nil
+
+	return s.save()
+}
+
+// Delete removes key from bucket.
+func (s *Store) Delete(bucket, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, ok := s.buckets[bucket][key]; !ok {
+		return nil
+	}
+
+	delete(s.buckets[bucket], key)
+
+	return s.save()
+}
+
+// Keys returns the sorted keys of bucket.
+func (s *Store) Keys(bucket string) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	keys := make([]string, 0, len(s.buckets[bucket]))
+
+	for key := range s.buckets[bucket] {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// saveDelay is the maximum delay of the changes saved by saveLater.
+const saveDelay = time.Second
+
+// saveLater schedules a save unless one is scheduled, the caller must hold the lock.
+func (s *Store) saveLater() {
+	if s.path == "" || s.dirty {
+		return
+	}
+
+	s.dirty = true
+
+	time.AfterFunc(saveDelay, func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		// The changes may be saved by another change in the meantime.
+		if !s.dirty {
+			return
+		}
+
+		if err := s.save(); err != nil {
+			log.WithError(err).Error("save store")
+		}
+	})
+}
+
+// save writes all buckets to the file, the caller must hold the lock.
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	s.dirty = false
+
+	conts, err := json.Marshal(s.buckets)
+
+	if err != nil {
+		return err
+	}
+
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	// Write to a temporary file first, so the store is never half written.
+	tmp := s.path + ".tmp"
+
+	if err := ioutil.WriteFile(tmp, conts, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,240 @@
+// Package telegram is a client of the Telegram Bot API, see https://core.telegram.org/bots/api.
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+)
+
+// DefaultBaseURL is the base URL of the Bot API.
+const DefaultBaseURL = "https://api.telegram.org"
+
+// SecretTokenHeader is the header of the secret token set by SetWebhook in updates.
+const SecretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// Client calls the methods of the bot of Token.
+type Client struct {
+	Token string
+	// BaseURL is DefaultBaseURL if it's empty, it can be a local stub in tests.
+	BaseURL    string
+	HttpClient *http.Client
+}
+
+// Error is an unsuccessful response of the Bot API.
+type Error struct {
+	Code        int    `json:"error_code"`
+	Description string `json:"description"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("[telegram error]: error_code: %d, description: %s", e.Code, e.Description)
+}
+
+type response struct {
+	Ok     bool            `json:"ok"`
+	Result json.RawMessage `json:"result"`
+	Error
+}
+
+type User struct {
+	Id           int64  `json:"id"`
+	IsBot        bool   `json:"is_bot"`
+	FirstName    string `json:"first_name"`
+	Username     string `json:"username,omitempty"`
+	LanguageCode string `json:"language_code,omitempty"`
+}
+
+// Chat is a private chat, a group or a channel.
+type Chat struct {
+	Id       int64  `json:"id"`
+	Type     string `json:"type"`
+	Title    string `json:"title,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+type Message struct {
+	MessageId int64  `json:"message_id"`
+	From      *User  `json:"from,omitempty"`
+	Chat      Chat   `json:"chat"`
+	Date      int64  `json:"date"`
+	Text      string `json:"text,omitempty"`
+}
+
+// Update is an incoming update of the webhook, only messages are handled.
+type Update struct {
+	UpdateId    int64    `json:"update_id"`
+	Message     *Message `json:"message,omitempty"`
+	ChannelPost *Message `json:"channel_post,omitempty"`
+}
+
+// Command returns the command of the message and its argument, e.g. `/until 12-25`
+// returns `until` and `12-25`. bot is the username of the bot: a command addressed
+// to a bot, e.g. `/year@progressbar_bot` in groups, is only a command of it if the
+// username is bot, case-insensitively. ok is false if the text is not a command of it.
+func (m *Message) Command(bot string) (command, arg string, ok bool) {
+	if !strings.HasPrefix(m.Text, "/") {
+		return
+	}
+
+	fields := strings.SplitN(m.Text[1:], " ", 2)
+	names := strings.SplitN(fields[0], "@", 2)
+	command = names[0]
+
+	if len(names) == 2 && !strings.EqualFold(names[1], strings.TrimPrefix(bot, "@")) {
+		return "", "", false
+	}
+
+	if len(fields) == 2 {
+		arg = strings.TrimSpace(fields[1])
+	}
+
+	return command, arg, command != ""
+}
+
+// NewClient returns the client of the bot of token, baseURL may be empty.
+// Requests fail after timeout, there is no timeout if it's 0.
+func NewClient(token, baseURL string, timeout time.Duration) *Client {
+	return &Client{
+		Token:      token,
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{Timeout: timeout},
+	}
+}
+
+func (c *Client) methodURL(method string) string {
+	base := c.BaseURL
+
+	if base == "" {
+		base = DefaultBaseURL
+	}
+
+	return strings.TrimRight(base, "/") + "/bot" + c.Token + "/" + method
+}
+
+// Call calls the method with the JSON body of params, and decodes the result into result.
+func (c *Client) Call(method string, params interface{}, result interface{}) (err error) {
+	body, err := json.Marshal(params)
+
+	if err != nil {
+		return
+	}
+
+	return c.do(method, "application/json", bytes.NewReader(body), result)
+}
+
+func (c *Client) do(method, contentType string, body io.Reader, result interface{}) (err error) {
+	log.WithField("method", method).Debug("<= telegram request")
+
+	res, err := c.HttpClient.Post(c.methodURL(method), contentType, body)
+
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(res.Body)
+
+	if err != nil {
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"status": res.StatusCode,
+		"body":   string(responseBody),
+	}).Debug("=> telegram response")
+
+	var r response
+
+	if err = json.Unmarshal(responseBody, &r); err != nil {
+		err = fmt.Errorf("http.Status: %s", res.Status)
+		return
+	}
+
+	if !r.Ok {
+		err = &r.Error
+		return
+	}
+
+	if result != nil {
+		err = json.Unmarshal(r.Result, result)
+	}
+
+	return
+}
+
+// GetMe returns the bot itself.
+func (c *Client) GetMe() (user User, err error) {
+	err = c.Call("getMe", nil, &user)
+	return
+}
+
+// SendMessage sends the text to the chat, chatId is the id, or `@username` of a channel.
+func (c *Client) SendMessage(chatId, text string) (message Message, err error) {
+	params := map[string]interface{}{
+		"chat_id": chatId,
+		"text":    text,
+	}
+
+	err = c.Call("sendMessage", params, &message)
+	return
+}
+
+// SendPhoto uploads the photo of the file name with the caption to the chat.
+func (c *Client) SendPhoto(chatId string, name string, photo []byte, caption string) (message Message, err error) {
+	var buf bytes.Buffer
+
+	w := multipart.NewWriter(&buf)
+
+	w.WriteField("chat_id", chatId)
+
+	if caption != "" {
+		w.WriteField("caption", caption)
+	}
+
+	part, err := w.CreateFormFile("photo", name)
+
+	if err != nil {
+		return
+	}
+
+	if _, err = part.Write(photo); err != nil {
+		return
+	}
+
+	if err = w.Close(); err != nil {
+		return
+	}
+
+	err = c.do("sendPhoto", w.FormDataContentType(), &buf, &message)
+	return
+}
+
+// SetWebhook sets the URL of updates, secretToken is sent in SecretTokenHeader if it's not empty.
+func (c *Client) SetWebhook(url, secretToken string) (err error) {
+	params := map[string]interface{}{
+		"url":             url,
+		"allowed_updates": []string{"message", "channel_post"},
+	}
+
+	if secretToken != "" {
+		params["secret_token"] = secretToken
+	}
+
+	return c.Call("setWebhook", params, nil)
+}
+
+// ChatId formats the id of a chat as the chat_id parameter.
+func ChatId(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,177 @@
+package telegram
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMessageCommand(t *testing.T) {
+	tests := []struct {
+		text    string
+		command string
+		arg     string
+		ok      bool
+	}{
+		{"/year", "year", "", true},
+		{"/year@progressbar_bot", "year", "", true},
+		{"/year@ProgressBar_Bot", "year", "", true},
+		{"/year@other_bot", "", "", false},
+		{"/until@other_bot 12-25", "", "", false},
+		{"/until 12-25", "until", "12-25", true},
+		{"/until@progressbar_bot  春节 ", "until", "春节", true},
+		{"year", "", "", false},
+		{"/", "", "", false},
+		{"", "", "", false},
+	}
+
+	for _, test := range tests {
+		m := Message{Text: test.text}
+		command, arg, ok := m.Command("progressbar_bot")
+
+		if command != test.command || arg != test.arg || ok != test.ok {
+			t.Errorf("Command() of %q = %q, %q, %v, want %q, %q, %v", test.text, command, arg, ok, test.command, test.arg, test.ok)
+		}
+	}
+}
+
+func TestClientCall(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendMessage" {
+			t.Errorf("path = %q, want /botTOKEN/sendMessage", r.URL.Path)
+		}
+
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+
+		var params map[string]string
+
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			t.Fatal(err)
+		}
+
+		if params["chat_id"] != "@progressbar" || params["text"] != "79%" {
+			t.Errorf("params = %v", params)
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":7,"chat":{"id":-100,"type":"channel"},"text":"79%"}}`))
+	}))
+	defer server.Close()
+
+	message, err := NewClient("TOKEN", server.URL+"/", time.Second).SendMessage("@progressbar", "79%")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.MessageId != 7 || message.Chat.Id != -100 || message.Text != "79%" {
+		t.Errorf("message = %+v", message)
+	}
+}
+
+func TestClientCallError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`))
+	}))
+	defer server.Close()
+
+	err := NewClient("TOKEN", server.URL, time.Second).Call("sendMessage", map[string]string{"chat_id": "1"}, nil)
+
+	e, ok := err.(*Error)
+
+	if !ok {
+		t.Fatalf("err = %v, want *Error", err)
+	}
+
+	if e.Code != 400 || e.Description != "Bad Request: chat not found" {
+		t.Errorf("err = %+v", e)
+	}
+}
+
+func TestClientCallInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := NewClient("TOKEN", server.URL, time.Second).Call("getMe", nil, nil)
+
+	if err == nil || err.Error() != "http.Status: 502 Bad Gateway" {
+		t.Errorf("err = %v, want the status", err)
+	}
+}
+
+func TestClientSendPhoto(t *testing.T) {
+	photo := []byte("\x89PNG")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/sendPhoto" {
+			t.Errorf("path = %q, want /botTOKEN/sendPhoto", r.URL.Path)
+		}
+
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+
+		if chatId := r.FormValue("chat_id"); chatId != "42" {
+			t.Errorf("chat_id = %q, want 42", chatId)
+		}
+
+		if caption := r.FormValue("caption"); caption != "2026" {
+			t.Errorf("caption = %q, want 2026", caption)
+		}
+
+		file, header, err := r.FormFile("photo")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defer file.Close()
+
+		conts, _ := ioutil.ReadAll(file)
+
+		if header.Filename != "cover.png" || string(conts) != string(photo) {
+			t.Errorf("photo = %q %q", header.Filename, conts)
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"message_id":8,"chat":{"id":42,"type":"private"}}}`))
+	}))
+	defer server.Close()
+
+	message, err := NewClient("TOKEN", server.URL, time.Second).SendPhoto(ChatId(42), "cover.png", photo, "2026")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if message.MessageId != 8 {
+		t.Errorf("message = %+v", message)
+	}
+}
+
+func TestClientGetMe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/botTOKEN/getMe" {
+			t.Errorf("path = %q, want /botTOKEN/getMe", r.URL.Path)
+		}
+
+		w.Write([]byte(`{"ok":true,"result":{"id":1,"is_bot":true,"first_name":"Progress","username":"progressbar_bot"}}`))
+	}))
+	defer server.Close()
+
+	user, err := NewClient("TOKEN", server.URL, time.Second).GetMe()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !user.IsBot || user.Username != "progressbar_bot" {
+		t.Errorf("user = %+v", user)
+	}
+}
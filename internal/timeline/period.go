@@ -0,0 +1,141 @@
+package timeline
+
+import (
+	"errors"
+	"time"
+)
+
+// Day returns the range of the day of t.
+func Day(t time.Time) [2]time.Time {
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	return [2]time.Time{start, start.AddDate(0, 0, 1)}
+}
+
+// Week returns the range of the week of t, which starts on Monday.
+func Week(t time.Time) [2]time.Time {
+	weekday := int(t.Weekday())
+
+	if weekday == 0 {
+		weekday = 7
+	}
+
+	start := time.Date(t.Year(), t.Month(), t.Day()-(weekday-1), 0, 0, 0, 0, time.UTC)
+
+	return [2]time.Time{start, start.AddDate(0, 0, 7)}
+}
+
+// Month returns the range of the month of t.
+func Month(t time.Time) [2]time.Time {
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	return [2]time.Time{start, start.AddDate(0, 1, 0)}
+}
+
+// Quarter returns the range of the quarter of t.
+func Quarter(t time.Time) [2]time.Time {
+	start := time.Date(t.Year(), (t.Month()-1)/3*3+1, 1, 0, 0, 0, 0, time.UTC)
+
+	return [2]time.Time{start, start.AddDate(0, 3, 0)}
+}
+
+// Year returns the range of the year.
+func Year(year int) [2]time.Time {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	return [2]time.Time{start, start.AddDate(1, 0, 0)}
+}
+
+// Progress gets the position of t in d as New, but it's 0 before d and 1 after d.
+func Progress(t time.Time, d [2]time.Time) float64 {
+	if t.Before(d[0]) {
+		return 0
+	}
+
+	if t.After(d[1]) {
+		return 1
+	}
+
+	ratio, _ := New(t, d)
+
+	return ratio
+}
+
+// DaysLeft returns the number of whole days from t to end, it's 0 if end is passed.
+func DaysLeft(t, end time.Time) int {
+	if !end.After(t) {
+		return 0
+	}
+
+	return int(end.Sub(t) / (24 * time.Hour))
+}
+
+// Annual returns the last occurrence of the date of month and day on or before t
+// and the next one after t. It's on the 28th in years without February 29.
+func Annual(t time.Time, month time.Month, day int) [2]time.Time {
+	occurrence := func(year int) time.Time {
+		date := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+		if date.Month() != month {
+			date = time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+		}
+
+		return date
+	}
+
+	today := Day(t)[0]
+	last := occurrence(t.Year())
+
+	if last.After(today) {
+		return [2]time.Time{occurrence(t.Year() - 1), last}
+	}
+
+	return [2]time.Time{last, occurrence(t.Year() + 1)}
+}
+
+// springFestivals are the dates of the Spring Festival, the lunar new year.
+var springFestivals = []time.Time{
+	time.Date(2023, time.January, 22, 0, 0, 0, 0, time.UTC),
+	time.Date(2024, time.February, 10, 0, 0, 0, 0, time.UTC),
+	time.Date(2025, time.January, 29, 0, 0, 0, 0, time.UTC),
+	time.Date(2026, time.February, 17, 0, 0, 0, 0, time.UTC),
+	time.Date(2027, time.February, 6, 0, 0, 0, 0, time.UTC),
+	time.Date(2028, time.January, 26, 0, 0, 0, 0, time.UTC),
+	time.Date(2029, time.February, 13, 0, 0, 0, 0, time.UTC),
+	time.Date(2030, time.February, 3, 0, 0, 0, 0, time.UTC),
+	time.Date(2031, time.January, 23, 0, 0, 0, 0, time.UTC),
+	time.Date(2032, time.February, 11, 0, 0, 0, 0, time.UTC),
+	time.Date(2033, time.January, 31, 0, 0, 0, 0, time.UTC),
+	time.Date(2034, time.February, 19, 0, 0, 0, 0, time.UTC),
+	time.Date(2035, time.February, 8, 0, 0, 0, 0, time.UTC),
+}
+
+// SpringFestival returns the last Spring Festival on or before t and the next one
+// after t, like Annual.
+func SpringFestival(t time.Time) ([2]time.Time, error) {
+	today := Day(t)[0]
+
+	for i := 1; i < len(springFestivals); i++ {
+		if springFestivals[i].After(today) && !springFestivals[i-1].After(today) {
+			return [2]time.Time{springFestivals[i-1], springFestivals[i]}, nil
+		}
+	}
+
+	return [2]time.Time{}, errors.New("the date of the Spring Festival is unknown")
+}
+
+// Wall returns the wall clock time of t as a time in UTC, which is what the
+// ranges of this package are based on, e.g. `Day(Wall(t))`.
+func Wall(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// In returns the range of wall clock times d, e.g. by Day, in loc. The length of
+// the range may differ from d over the transitions of daylight saving time.
+func In(d [2]time.Time, loc *time.Location) [2]time.Time {
+	for i, t := range d {
+		d[i] = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+	}
+
+	return d
+}
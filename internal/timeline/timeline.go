@@ -67,6 +67,26 @@ func NewWithMonth(t time.Time) (float64, error) {
 	return ratio, nil
 }
 
+func NewWithQuarter(t time.Time) (float64, error) {
+	year := t.Year()
+	firstMonth := (t.Month()-1)/3*3 + 1
+
+	start := time.Date(year, firstMonth, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(year, firstMonth+3, 1, 0, 0, 0, 0, time.UTC)
+
+	d := [2]time.Time{start, end}
+
+	ratio, err := New(t, d)
+
+	if err != nil {
+		return -1, err
+	}
+
+	log.Debugf("progress of Q%d is %v", (firstMonth+2)/3, ratio)
+
+	return ratio, nil
+}
+
 func NewWithWeek(t time.Time) (float64, error) {
   year := t.Year()
   month := t.Month()
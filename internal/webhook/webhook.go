@@ -0,0 +1,159 @@
+// Package webhook posts signed requests to outgoing webhooks with retries.
+//
+// A request is signed by HMAC-SHA256 of the timestamp and the body joined by ".",
+// the receiver verifies it with Verify, or by computing
+//
+//	hex(HMAC-SHA256(secret, X-Progressbar-Timestamp + "." + body))
+//
+// and comparing it with X-Progressbar-Signature without the `sha256=` prefix.
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers of requests.
+const (
+	HeaderSignature = "X-Progressbar-Signature"
+	HeaderTimestamp = "X-Progressbar-Timestamp"
+	// HeaderDelivery identifies the delivery, it's the same for retries.
+	HeaderDelivery = "X-Progressbar-Delivery"
+)
+
+// Endpoint is the receiver of a webhook.
+type Endpoint struct {
+	Name string
+	URL  string
+	// ContentType is `application/json` if it's empty.
+	ContentType string
+	// Secret signs the requests, they're not signed if it's empty.
+	Secret  string
+	Headers map[string]string
+}
+
+// Sign returns the signature of the body sent at timestamp, in seconds.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is the signature of the body sent at timestamp.
+func Verify(secret, signature string, timestamp int64, body []byte) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body)))
+}
+
+// Error is a response of an unexpected status.
+type Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("http.Status: %d %s", e.StatusCode, e.Body)
+}
+
+// Temporary reports whether the request may succeed if it's retried,
+// e.g. the receiver is unavailable or rate limited.
+func (e *Error) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Sender sends requests to endpoints.
+type Sender struct {
+	HttpClient *http.Client
+	// Attempts is the maximum number of attempts of a request.
+	Attempts int
+	// Backoff is the delay before the first retry, it's doubled for each retry.
+	Backoff time.Duration
+}
+
+// Send posts the body to the endpoint, and retries it if it fails temporarily.
+// attempts is the number of attempts made, and err is the error of the last one.
+func (s *Sender) Send(e Endpoint, delivery string, body []byte) (attempts int, err error) {
+	backoff := s.Backoff
+
+	for attempts < s.Attempts || attempts == 0 {
+		if attempts > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		attempts++
+
+		if err = s.post(e, delivery, body); err == nil {
+			return
+		}
+
+		if herr, ok := err.(*Error); ok && !herr.Temporary() {
+			return
+		}
+	}
+
+	return
+}
+
+func (s *Sender) post(e Endpoint, delivery string, body []byte) (err error) {
+	req, err := http.NewRequest("POST", e.URL, bytes.NewReader(body))
+
+	if err != nil {
+		return
+	}
+
+	contentType := e.ContentType
+
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "progressbar201X")
+	req.Header.Set(HeaderDelivery, delivery)
+
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+
+	if e.Secret != "" {
+		timestamp := time.Now().Unix()
+
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(HeaderSignature, Sign(e.Secret, timestamp, body))
+	}
+
+	client := s.HttpClient
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+
+	if err != nil {
+		return
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		io.Copy(ioutil.Discard, res.Body)
+		return nil
+	}
+
+	// Only the beginning of the body is kept in the error.
+	responseBody, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+
+	return &Error{res.StatusCode, strings.TrimSpace(string(responseBody))}
+}
@@ -0,0 +1,132 @@
+package webhook
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// echo -n '1700000000.{"percent":79}' | openssl dgst -sha256 -hmac secret
+	want := "sha256=9149dacb89e682463c8dfad4f8111ec9a250dad20239d5827f460bd2f0366d6e"
+
+	if got := Sign("secret", 1700000000, []byte(`{"percent":79}`)); got != want {
+		t.Errorf("Sign() = %q, want %q", got, want)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"percent":79}`)
+	signature := Sign("secret", 1700000000, body)
+
+	tests := []struct {
+		secret    string
+		signature string
+		timestamp int64
+		body      string
+		want      bool
+	}{
+		{"secret", signature, 1700000000, `{"percent":79}`, true},
+		{"other", signature, 1700000000, `{"percent":79}`, false},
+		{"secret", signature, 1700000001, `{"percent":79}`, false},
+		{"secret", signature, 1700000000, `{"percent":80}`, false},
+		{"secret", signature[len("sha256="):], 1700000000, `{"percent":79}`, false},
+	}
+
+	for i, test := range tests {
+		if got := Verify(test.secret, test.signature, test.timestamp, []byte(test.body)); got != test.want {
+			t.Errorf("#%d: Verify() = %v, want %v", i, got, test.want)
+		}
+	}
+}
+
+func TestSenderSend(t *testing.T) {
+	body := []byte(`{"percent":79}`)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		conts, _ := ioutil.ReadAll(r.Body)
+
+		if !Verify("secret", r.Header.Get(HeaderSignature), timestamp, conts) {
+			t.Error("invalid signature")
+		}
+
+		if r.Header.Get(HeaderDelivery) != "2026-10-19#hook" {
+			t.Errorf("delivery = %q", r.Header.Get(HeaderDelivery))
+		}
+
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Custom") != "1" {
+			t.Errorf("headers = %v", r.Header)
+		}
+	}))
+	defer server.Close()
+
+	s := &Sender{Attempts: 3}
+	e := Endpoint{Name: "hook", URL: server.URL, Secret: "secret", Headers: map[string]string{"X-Custom": "1"}}
+
+	attempts, err := s.Send(e, "2026-10-19#hook", body)
+
+	if err != nil || attempts != 1 {
+		t.Errorf("Send() = %d, %v, want 1, nil", attempts, err)
+	}
+}
+
+func TestSenderSendRetries(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{http.StatusServiceUnavailable, 3},
+		{http.StatusTooManyRequests, 3},
+		{http.StatusBadRequest, 1},
+		{http.StatusNotFound, 1},
+	}
+
+	for _, test := range tests {
+		var requests int32
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.Error(w, "failed", test.status)
+		}))
+
+		attempts, err := (&Sender{Attempts: 3}).Send(Endpoint{URL: server.URL}, "d", nil)
+
+		server.Close()
+
+		e, ok := err.(*Error)
+
+		if !ok || e.StatusCode != test.status || e.Body != "failed" {
+			t.Errorf("%d: err = %v", test.status, err)
+		}
+
+		if attempts != test.attempts || int(requests) != test.attempts {
+			t.Errorf("%d: attempts = %d, requests = %d, want %d", test.status, attempts, requests, test.attempts)
+		}
+	}
+}
+
+func TestSenderSendRecovers(t *testing.T) {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	attempts, err := (&Sender{Attempts: 3}).Send(Endpoint{URL: server.URL}, "d", nil)
+
+	if err != nil || attempts != 2 {
+		t.Errorf("Send() = %d, %v, want 2, nil", attempts, err)
+	}
+}
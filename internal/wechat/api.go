@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 )
 
 type accessTokenResponse struct {
@@ -15,12 +16,13 @@ type accessTokenResponse struct {
 
 func fetchAccessToken(c *http.Client, appId, appSecret string) (result *accessTokenResponse, err error) {
 	res, err := c.Get("https://api.weixin.qq.com/cgi-bin/token?grant_type=client_credential&appid=" + appId + "&secret=" + appSecret)
-	defer res.Body.Close()
 
 	if err != nil {
 		return
 	}
 
+	defer res.Body.Close()
+
 	if res.StatusCode != http.StatusOK {
 		err = fmt.Errorf("http.Status: %s", res.Status)
 		return
@@ -93,8 +95,18 @@ func fetchImageMaterial(client *Client) (materials []Material, err error) {
 }
 
 func UploadArticleMaterial(client *Client, article *ArticleMaterial) (mediaId string, err error) {
+	return UploadNewsMaterial(client, []*ArticleMaterial{article})
+}
+
+// UploadNewsMaterial uploads a news material of up to `MaxNewsArticles` articles.
+func UploadNewsMaterial(client *Client, articles []*ArticleMaterial) (mediaId string, err error) {
 	apiURL := "https://api.weixin.qq.com/cgi-bin/material/add_news"
 
+	if len(articles) == 0 || len(articles) > MaxNewsArticles {
+		err = fmt.Errorf("a news material has 1 to %d articles, got %d", MaxNewsArticles, len(articles))
+		return
+	}
+
 	var result struct {
 		WechatGlobalError
 		Type      string `json:"type"`
@@ -106,7 +118,7 @@ func UploadArticleMaterial(client *Client, article *ArticleMaterial) (mediaId st
 		Articles []*ArticleMaterial `json:"articles"`
 	}{}
 
-	data.Articles = []*ArticleMaterial{article}
+	data.Articles = articles
 
 	if err = client.Post(apiURL, &data, &result); err != nil {
 		return
@@ -122,6 +134,13 @@ func UploadArticleMaterial(client *Client, article *ArticleMaterial) (mediaId st
 }
 
 func BetchPostArticle(client *Client, mediaId string) (err error) {
+	_, err = SendMassNews(client, mediaId, 2)
+	return
+}
+
+// SendMassNews sends the news material to the followers of the tag, the result
+// is reported later by a MassSendJobFinishEvent, or GetMassStatus with msgId.
+func SendMassNews(client *Client, mediaId string, tagId int) (msgId int64, err error) {
 	apiURL := "https://api.weixin.qq.com/cgi-bin/message/mass/sendall"
 
 	var result struct {
@@ -142,7 +161,7 @@ func BetchPostArticle(client *Client, mediaId string) (err error) {
 	}{}
 
 	data.Filter.IsToAll = false
-	data.Filter.TagId = 2
+	data.Filter.TagId = tagId
 	data.Mpnews.MediaId = mediaId
 	data.MsgType = "mpnews"
 
@@ -155,5 +174,62 @@ func BetchPostArticle(client *Client, mediaId string) (err error) {
 		return
 	}
 
+	msgId = result.MsgId
+	return
+}
+
+// PreviewNews sends the news material to the follower only, to check it before a mass send.
+func PreviewNews(client *Client, openId, mediaId string) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/message/mass/preview"
+
+	var result WechatGlobalError
+
+	var data = struct {
+		ToUser string `json:"touser"`
+		Mpnews struct {
+			MediaId string `json:"media_id"`
+		} `json:"mpnews"`
+		MsgType string `json:"msgtype"`
+	}{}
+
+	data.ToUser = openId
+	data.Mpnews.MediaId = mediaId
+	data.MsgType = "mpnews"
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// GetMassStatus returns the status of the mass send of msgId, e.g. SEND_SUCCESS.
+func GetMassStatus(client *Client, msgId int64) (status string, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/message/mass/get"
+
+	var result struct {
+		WechatGlobalError
+		MsgStatus string `json:"msg_status"`
+	}
+
+	var data = struct {
+		MsgId string `json:"msg_id"`
+	}{strconv.FormatInt(msgId, 10)}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	status = result.MsgStatus
 	return
 }
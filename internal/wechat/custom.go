@@ -0,0 +1,161 @@
+package wechat
+
+import (
+	"fmt"
+	"time"
+)
+
+// CustomMessageWindow is how long customer service messages can be sent to a
+// follower after the follower interacts with the account.
+const CustomMessageWindow = 48 * time.Hour
+
+// Types of customer service messages.
+const (
+	CustomMsgTypeText   = "text"
+	CustomMsgTypeImage  = "image"
+	CustomMsgTypeNews   = "news"
+	CustomMsgTypeMpNews = "mpnews"
+	CustomMsgTypeMenu   = "msgmenu"
+)
+
+// CustomMessage is a customer service message, see SendCustomMessage.
+type CustomMessage interface {
+	CustomMsgType() string
+}
+
+// CustomText is a text message, it may contain links.
+type CustomText struct {
+	Content string `json:"content"`
+}
+
+// CustomImage is an image uploaded as media.
+type CustomImage struct {
+	MediaId string `json:"media_id"`
+}
+
+// CustomNews is a news card linking to URL, only one article is accepted.
+type CustomNews struct {
+	Articles []CustomArticle `json:"articles"`
+}
+
+// CustomArticle is an article of CustomNews.
+type CustomArticle struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	PicURL      string `json:"picurl"`
+}
+
+// CustomMpNews is a news material, e.g. the uploaded article of a broadcast.
+type CustomMpNews struct {
+	MediaId string `json:"media_id"`
+}
+
+// CustomMenu is a message with a list of options, the click of an option is sent
+// back as a text message of its content.
+type CustomMenu struct {
+	HeadContent string           `json:"head_content"`
+	List        []CustomMenuItem `json:"list"`
+	TailContent string           `json:"tail_content"`
+}
+
+// CustomMenuItem is an option of CustomMenu.
+type CustomMenuItem struct {
+	Id      string `json:"id"`
+	Content string `json:"content"`
+}
+
+func (CustomText) CustomMsgType() string   { return CustomMsgTypeText }
+func (CustomImage) CustomMsgType() string  { return CustomMsgTypeImage }
+func (CustomNews) CustomMsgType() string   { return CustomMsgTypeNews }
+func (CustomMpNews) CustomMsgType() string { return CustomMsgTypeMpNews }
+func (CustomMenu) CustomMsgType() string   { return CustomMsgTypeMenu }
+
+// SendCustomMessage sends a customer service message to the follower, who must
+// have interacted with the account within `CustomMessageWindow`.
+func SendCustomMessage(client *Client, openId string, message CustomMessage) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/message/custom/send"
+
+	switch news := message.(type) {
+	case CustomNews:
+		err = checkCustomNews(&news)
+	case *CustomNews:
+		err = checkCustomNews(news)
+	}
+
+	if err != nil {
+		return
+	}
+
+	var result WechatGlobalError
+
+	msgType := message.CustomMsgType()
+
+	// The body is keyed by the type, e.g. {"touser": "", "msgtype": "text", "text": {}}.
+	data := map[string]interface{}{
+		"touser":  openId,
+		"msgtype": msgType,
+		msgType:   message,
+	}
+
+	if err = client.Post(apiURL, data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// SendCustomText sends a text customer service message to the follower.
+func SendCustomText(client *Client, openId, content string) (err error) {
+	return SendCustomMessage(client, openId, CustomText{content})
+}
+
+// SetTyping shows or hides the typing status to the follower in the chat,
+// it lasts 15 seconds unless a message is sent.
+func SetTyping(client *Client, openId string, typing bool) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/message/custom/typing"
+
+	command := "CancelTyping"
+
+	if typing {
+		command = "Typing"
+	}
+
+	var result WechatGlobalError
+
+	var data = struct {
+		ToUser  string `json:"touser"`
+		Command string `json:"command"`
+	}{openId, command}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// checkCustomNews checks that news has exactly 1 article.
+func checkCustomNews(news *CustomNews) error {
+	var n int
+
+	if news != nil {
+		n = len(news.Articles)
+	}
+
+	if n != 1 {
+		return fmt.Errorf("a news message has 1 article, got %d", n)
+	}
+
+	return nil
+}
@@ -0,0 +1,259 @@
+package wechat
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Types of menu buttons.
+const (
+	ButtonTypeClick           = "click"
+	ButtonTypeView            = "view"
+	ButtonTypeMiniProgram     = "miniprogram"
+	ButtonTypeScancodePush    = "scancode_push"
+	ButtonTypeScancodeWaitMsg = "scancode_waitmsg"
+	ButtonTypePicSysPhoto     = "pic_sysphoto"
+	ButtonTypePicPhotoOrAlbum = "pic_photo_or_album"
+	ButtonTypePicWeixin       = "pic_weixin"
+	ButtonTypeLocationSelect  = "location_select"
+	ButtonTypeMediaId         = "media_id"
+	ButtonTypeArticleId       = "article_id"
+	ButtonTypeViewLimited     = "view_limited"
+)
+
+// Limits of a menu, lengths of names and keys are counted in bytes.
+const (
+	MaxMenuButtons         = 3
+	MaxMenuSubButtons      = 5
+	MaxButtonNameLength    = 16
+	MaxSubButtonNameLength = 60
+	MaxButtonKeyLength     = 128
+	MaxButtonURLLength     = 1024
+)
+
+// Button is a button of a menu, a button with SubButtons has no type.
+type Button struct {
+	Type       string   `json:"type,omitempty" yaml:"type"`
+	Name       string   `json:"name" yaml:"name"`
+	Key        string   `json:"key,omitempty" yaml:"key"`
+	URL        string   `json:"url,omitempty" yaml:"url"`
+	MediaId    string   `json:"media_id,omitempty" yaml:"media_id"`
+	ArticleId  string   `json:"article_id,omitempty" yaml:"article_id"`
+	AppId      string   `json:"appid,omitempty" yaml:"appid"`
+	PagePath   string   `json:"pagepath,omitempty" yaml:"pagepath"`
+	SubButtons []Button `json:"sub_button,omitempty" yaml:"sub_button"`
+}
+
+// MatchRule selects the followers of a personalized menu.
+type MatchRule struct {
+	TagId string `json:"tag_id,omitempty" yaml:"tag_id"`
+	// ClientPlatformType is 1 for iOS, 2 for Android and 3 for others.
+	ClientPlatformType string `json:"client_platform_type,omitempty" yaml:"client_platform_type"`
+}
+
+// Menu is the custom menu of the account, or a personalized menu if MatchRule is set.
+type Menu struct {
+	Buttons   []Button   `json:"button" yaml:"button"`
+	MatchRule *MatchRule `json:"matchrule,omitempty" yaml:"matchrule"`
+	MenuId    int64      `json:"menuid,omitempty" yaml:"-"`
+}
+
+// Validate checks the menu against the limits of WeChat.
+func (m *Menu) Validate() error {
+	if len(m.Buttons) == 0 || len(m.Buttons) > MaxMenuButtons {
+		return fmt.Errorf("a menu has 1 to %d buttons, got %d", MaxMenuButtons, len(m.Buttons))
+	}
+
+	for _, b := range m.Buttons {
+		if len(b.SubButtons) > MaxMenuSubButtons {
+			return fmt.Errorf("button %q has more than %d sub buttons", b.Name, MaxMenuSubButtons)
+		}
+
+		if err := b.validate(MaxButtonNameLength, len(b.SubButtons) > 0); err != nil {
+			return err
+		}
+
+		for _, sub := range b.SubButtons {
+			if len(sub.SubButtons) > 0 {
+				return fmt.Errorf("sub button %q can't have sub buttons", sub.Name)
+			}
+
+			if err := sub.validate(MaxSubButtonNameLength, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *Button) validate(maxNameLength int, parent bool) error {
+	if b.Name == "" || len(b.Name) > maxNameLength {
+		return fmt.Errorf("name of button %q should be 1 to %d bytes", b.Name, maxNameLength)
+	}
+
+	if parent {
+		return nil
+	}
+
+	switch b.Type {
+	case ButtonTypeClick, ButtonTypeScancodePush, ButtonTypeScancodeWaitMsg, ButtonTypePicSysPhoto,
+		ButtonTypePicPhotoOrAlbum, ButtonTypePicWeixin, ButtonTypeLocationSelect:
+		if b.Key == "" || len(b.Key) > MaxButtonKeyLength {
+			return fmt.Errorf("key of button %q should be 1 to %d bytes", b.Name, MaxButtonKeyLength)
+		}
+	case ButtonTypeView:
+		if b.URL == "" || len(b.URL) > MaxButtonURLLength {
+			return fmt.Errorf("url of button %q should be 1 to %d bytes", b.Name, MaxButtonURLLength)
+		}
+	case ButtonTypeMiniProgram:
+		if b.URL == "" || b.AppId == "" || b.PagePath == "" {
+			return fmt.Errorf("button %q needs url, appid and pagepath", b.Name)
+		}
+	case ButtonTypeMediaId, ButtonTypeViewLimited:
+		if b.MediaId == "" {
+			return fmt.Errorf("button %q needs media_id", b.Name)
+		}
+	case ButtonTypeArticleId:
+		if b.ArticleId == "" {
+			return fmt.Errorf("button %q needs article_id", b.Name)
+		}
+	default:
+		return fmt.Errorf("button %q has unknown type %q", b.Name, b.Type)
+	}
+
+	return nil
+}
+
+// ClickKeys returns the keys of the CLICK buttons of the menu.
+func (m *Menu) ClickKeys() []string {
+	var keys []string
+
+	for _, b := range m.Buttons {
+		for _, button := range append([]Button{b}, b.SubButtons...) {
+			if button.Type == ButtonTypeClick {
+				keys = append(keys, button.Key)
+			}
+		}
+	}
+
+	return keys
+}
+
+// CreateMenu creates the custom menu, which replaces the current one.
+func CreateMenu(client *Client, menu *Menu) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/menu/create"
+
+	var result WechatGlobalError
+
+	data := Menu{Buttons: menu.Buttons}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// GetMenu returns the custom menu and the personalized menus,
+// menu is nil if there isn't any.
+func GetMenu(client *Client) (menu *Menu, conditional []Menu, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/menu/get"
+
+	var result struct {
+		WechatGlobalError
+		Menu            *Menu  `json:"menu"`
+		ConditionalMenu []Menu `json:"conditionalmenu"`
+	}
+
+	if err = client.Get(apiURL, "", &result); err != nil {
+		return
+	}
+
+	// 46003 means no menu is created.
+	if result.ErrCode == 46003 {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	menu = result.Menu
+	conditional = result.ConditionalMenu
+	return
+}
+
+// DeleteMenu deletes the custom menu and all personalized menus.
+func DeleteMenu(client *Client) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/menu/delete"
+
+	var result WechatGlobalError
+
+	if err = client.Get(apiURL, "", &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// DeleteConditionalMenu deletes the personalized menu of menuId.
+func DeleteConditionalMenu(client *Client, menuId int64) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/menu/delconditional"
+
+	var result WechatGlobalError
+
+	data := map[string]int64{"menuid": menuId}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// AddConditionalMenu creates a personalized menu for the followers matching
+// `menu.MatchRule`, the custom menu must be created first.
+func AddConditionalMenu(client *Client, menu *Menu) (menuId int64, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/menu/addconditional"
+
+	if menu.MatchRule == nil {
+		err = fmt.Errorf("a personalized menu needs a match rule")
+		return
+	}
+
+	var result struct {
+		WechatGlobalError
+		MenuId json.Number `json:"menuid"`
+	}
+
+	data := Menu{Buttons: menu.Buttons, MatchRule: menu.MatchRule}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	menuId, err = result.MenuId.Int64()
+	return
+}
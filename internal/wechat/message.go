@@ -0,0 +1,297 @@
+package wechat
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+)
+
+// Types of inbound messages.
+const (
+	MsgTypeText       = "text"
+	MsgTypeImage      = "image"
+	MsgTypeVoice      = "voice"
+	MsgTypeVideo      = "video"
+	MsgTypeShortVideo = "shortvideo"
+	MsgTypeLocation   = "location"
+	MsgTypeLink       = "link"
+	MsgTypeEvent      = "event"
+)
+
+// Types of inbound events.
+const (
+	EventSubscribe         = "subscribe"
+	EventUnsubscribe       = "unsubscribe"
+	EventScan              = "SCAN"
+	EventLocation          = "LOCATION"
+	EventClick             = "CLICK"
+	EventView              = "VIEW"
+	EventMassSendJobFinish = "MASSSENDJOBFINISH"
+	// EventTemplateSendJobFinish reports the result of a template message.
+	EventTemplateSendJobFinish = "TEMPLATESENDJOBFINISH"
+)
+
+// Encryption modes of messages configured in the console of the account.
+const (
+	// EncryptModePlaintext sends messages in plaintext.
+	EncryptModePlaintext = "plaintext"
+	// EncryptModeCompatible sends messages in both plaintext and ciphertext.
+	EncryptModeCompatible = "compatible"
+	// EncryptModeSafe sends messages in ciphertext only.
+	EncryptModeSafe = "safe"
+)
+
+// Message is an inbound message or event parsed by ParseMessage,
+// use a type switch to get the concrete type, e.g. *TextMessage or *ClickEvent.
+type Message interface {
+	Header() *MessageHeader
+}
+
+// MessageHeader holds the fields shared by all inbound messages.
+type MessageHeader struct {
+	ToUserName   string `xml:"ToUserName"`
+	FromUserName string `xml:"FromUserName"`
+	CreateTime   int64  `xml:"CreateTime"`
+	MsgType      string `xml:"MsgType"`
+	// Event is only set for events.
+	Event string `xml:"Event"`
+}
+
+func (h *MessageHeader) Header() *MessageHeader {
+	return h
+}
+
+// KeyedEvent is an event with EventKey, e.g. *ClickEvent.
+type KeyedEvent interface {
+	Message
+	Key() string
+}
+
+func (e *SubscribeEvent) Key() string { return e.EventKey }
+func (e *ScanEvent) Key() string      { return e.EventKey }
+func (e *ClickEvent) Key() string     { return e.EventKey }
+func (e *ViewEvent) Key() string      { return e.EventKey }
+
+// MessageId returns the MsgId of m, it's 0 for events.
+func MessageId(m Message) int64 {
+	switch v := m.(type) {
+	case *TextMessage:
+		return v.MsgId
+	case *ImageMessage:
+		return v.MsgId
+	case *VoiceMessage:
+		return v.MsgId
+	case *VideoMessage:
+		return v.MsgId
+	case *LocationMessage:
+		return v.MsgId
+	case *LinkMessage:
+		return v.MsgId
+	}
+
+	return 0
+}
+
+type TextMessage struct {
+	MessageHeader
+	MsgId   int64  `xml:"MsgId"`
+	Content string `xml:"Content"`
+}
+
+type ImageMessage struct {
+	MessageHeader
+	MsgId   int64  `xml:"MsgId"`
+	PicUrl  string `xml:"PicUrl"`
+	MediaId string `xml:"MediaId"`
+}
+
+type VoiceMessage struct {
+	MessageHeader
+	MsgId   int64  `xml:"MsgId"`
+	MediaId string `xml:"MediaId"`
+	Format  string `xml:"Format"`
+	// Recognition is only set if speech recognition is enabled.
+	Recognition string `xml:"Recognition"`
+}
+
+// VideoMessage is a video or short video message, see MsgType.
+type VideoMessage struct {
+	MessageHeader
+	MsgId        int64  `xml:"MsgId"`
+	MediaId      string `xml:"MediaId"`
+	ThumbMediaId string `xml:"ThumbMediaId"`
+}
+
+type LocationMessage struct {
+	MessageHeader
+	MsgId     int64   `xml:"MsgId"`
+	Latitude  float64 `xml:"Location_X"`
+	Longitude float64 `xml:"Location_Y"`
+	Scale     int     `xml:"Scale"`
+	Label     string  `xml:"Label"`
+}
+
+type LinkMessage struct {
+	MessageHeader
+	MsgId       int64  `xml:"MsgId"`
+	Title       string `xml:"Title"`
+	Description string `xml:"Description"`
+	Url         string `xml:"Url"`
+}
+
+// SubscribeEvent is sent when a user follows the account,
+// EventKey is `qrscene_` followed by the scene if it's followed by scanning a QR code.
+type SubscribeEvent struct {
+	MessageHeader
+	EventKey string `xml:"EventKey"`
+	Ticket   string `xml:"Ticket"`
+}
+
+type UnsubscribeEvent struct {
+	MessageHeader
+}
+
+// ScanEvent is sent when a follower scans a QR code with scene.
+type ScanEvent struct {
+	MessageHeader
+	EventKey string `xml:"EventKey"`
+	Ticket   string `xml:"Ticket"`
+}
+
+// LocationEvent reports the location of a follower.
+type LocationEvent struct {
+	MessageHeader
+	Latitude  float64 `xml:"Latitude"`
+	Longitude float64 `xml:"Longitude"`
+	Precision float64 `xml:"Precision"`
+}
+
+// ClickEvent is sent when a follower clicks a CLICK menu.
+type ClickEvent struct {
+	MessageHeader
+	EventKey string `xml:"EventKey"`
+}
+
+// ViewEvent is sent when a follower clicks a VIEW menu, EventKey is the URL.
+type ViewEvent struct {
+	MessageHeader
+	EventKey string `xml:"EventKey"`
+	MenuId   string `xml:"MenuId"`
+}
+
+// MassSendJobFinishEvent reports the result of a mass send.
+type MassSendJobFinishEvent struct {
+	MessageHeader
+	MsgId       int64  `xml:"MsgID"`
+	Status      string `xml:"Status"`
+	TotalCount  int    `xml:"TotalCount"`
+	FilterCount int    `xml:"FilterCount"`
+	SentCount   int    `xml:"SentCount"`
+	ErrorCount  int    `xml:"ErrorCount"`
+}
+
+// TemplateSendJobFinishEvent reports the result of a template message, Status is
+// `success`, `failed:user block` or `failed: system failed`.
+type TemplateSendJobFinishEvent struct {
+	MessageHeader
+	MsgId  int64  `xml:"MsgID"`
+	Status string `xml:"Status"`
+}
+
+// UnknownMessage is a message or event of an unsupported type.
+type UnknownMessage struct {
+	MessageHeader
+	Raw []byte `xml:"-"`
+}
+
+// EncryptedMessage is the envelope of a message in safe mode.
+type EncryptedMessage struct {
+	XMLName    xml.Name `xml:"xml"`
+	ToUserName string   `xml:"ToUserName"`
+	Encrypt    string   `xml:"Encrypt"`
+}
+
+// ParseEncryptedMessage parses the envelope of a message in safe mode.
+func ParseEncryptedMessage(data []byte) (*EncryptedMessage, error) {
+	var m EncryptedMessage
+
+	if err := xml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+
+	if m.Encrypt == "" {
+		return nil, errors.New("Invalid value of encrypt")
+	}
+
+	return &m, nil
+}
+
+// ParseMessage parses the plaintext XML of an inbound message or event.
+func ParseMessage(data []byte) (Message, error) {
+	var header struct {
+		XMLName xml.Name
+		MessageHeader
+	}
+
+	if err := xml.Unmarshal(data, &header); err != nil {
+		return nil, err
+	}
+
+	if header.XMLName.Local != "xml" {
+		return nil, errors.New("Invalid message")
+	}
+
+	var m Message
+
+	switch header.MsgType {
+	case MsgTypeText:
+		m = &TextMessage{}
+	case MsgTypeImage:
+		m = &ImageMessage{}
+	case MsgTypeVoice:
+		m = &VoiceMessage{}
+	case MsgTypeVideo, MsgTypeShortVideo:
+		m = &VideoMessage{}
+	case MsgTypeLocation:
+		m = &LocationMessage{}
+	case MsgTypeLink:
+		m = &LinkMessage{}
+	case MsgTypeEvent:
+		m = newEvent(header.Event)
+	default:
+		m = &UnknownMessage{}
+	}
+
+	if err := xml.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+
+	if unknown, ok := m.(*UnknownMessage); ok {
+		unknown.Raw = data
+	}
+
+	return m, nil
+}
+
+func newEvent(event string) Message {
+	switch strings.ToUpper(event) {
+	case strings.ToUpper(EventSubscribe):
+		return &SubscribeEvent{}
+	case strings.ToUpper(EventUnsubscribe):
+		return &UnsubscribeEvent{}
+	case EventScan:
+		return &ScanEvent{}
+	case EventLocation:
+		return &LocationEvent{}
+	case EventClick:
+		return &ClickEvent{}
+	case EventView:
+		return &ViewEvent{}
+	case EventMassSendJobFinish:
+		return &MassSendJobFinishEvent{}
+	case EventTemplateSendJobFinish:
+		return &TemplateSendJobFinishEvent{}
+	}
+
+	return &UnknownMessage{}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/sha1"
+	"crypto/subtle"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -39,6 +40,17 @@ func Sign(token, timestamp, nonce, msg_encrypt string) string {
 	return hex.EncodeToString(hashsum[:])
 }
 
+// CheckSignature reports whether signature is the signature of the parameters,
+// it compares in constant time. msg_encrypt is empty for the URL signature.
+func CheckSignature(signature, token, timestamp, nonce, msg_encrypt string) bool {
+	expected := Sign(token, timestamp, nonce, msg_encrypt)
+
+	return subtle.ConstantTimeCompare([]byte(signature), []byte(expected)) == 1
+}
+
+// ParseXML parses XML into a map.
+//
+// Deprecated: use ParseMessage, which returns typed messages.
 func ParseXML(xml []byte) (map[string]interface{}, error) {
 	m, err := mxj.NewMapXml(xml)
 
@@ -271,12 +283,13 @@ func (client *Client) Get(apiURL string, querystring string, response interface{
 	logRequest("GET", uri, []byte{})
 
 	res, err := client.HttpClient.Get(uri)
-	defer res.Body.Close()
 
 	if err != nil {
 		return
 	}
 
+	defer res.Body.Close()
+
 	if res.StatusCode != http.StatusOK {
 		err = fmt.Errorf("http.Status: %s", res.Status)
 		return
@@ -322,12 +335,13 @@ func (client *Client) Post(apiURL string, data interface{}, response interface{}
 	logRequest("POST", uri, requestBodyBytes)
 
 	res, err := client.HttpClient.Post(uri, "application/json; charset=utf-8", requestBody)
-	defer res.Body.Close()
 
 	if err != nil {
 		return
 	}
 
+	defer res.Body.Close()
+
 	if res.StatusCode != http.StatusOK {
 		err = fmt.Errorf("http.Status: %s", res.Status)
 		return
@@ -402,17 +416,6 @@ func GetRandomImageMaterial(client *Client) (randomMaterial Material, err error)
 	return
 }
 
-// func GetAllTags(client *Client) (tags []Tag, err error) {
-// 	tags, err = fetchAllTags(client)
-
-// 	if err != nil {
-// 		fmt.Println(err)
-// 		return
-// 	}
-
-// 	return
-// }
-
 // func UploadArticleMaterial(client *Client, article *ArticleMaterial) (mediaId string, err error) {
 // 	mediaId, err = uploadArticleMaterial(client, article)
 
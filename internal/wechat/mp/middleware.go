@@ -0,0 +1,171 @@
+package mp
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/cache"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// Logging logs every message with the time spent and the result.
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(m wechat.Message) (wechat.Reply, error) {
+			start := time.Now()
+			header := m.Header()
+
+			reply, err := next(m)
+
+			logger := log.WithFields(log.Fields{
+				"from":     header.FromUserName,
+				"type":     header.MsgType,
+				"event":    header.Event,
+				"duration": time.Since(start).String(),
+			})
+
+			if err != nil {
+				logger.WithError(err).Error("handle message")
+			} else {
+				logger.Info("handle message")
+			}
+
+			return reply, err
+		}
+	}
+}
+
+// Recover turns a panic of the handler into an error.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(m wechat.Message) (reply wechat.Reply, err error) {
+			defer func() {
+				if v := recover(); v != nil {
+					log.WithField("stack", string(debug.Stack())).Errorf("handler panic: %v", v)
+
+					reply = nil
+					err = fmt.Errorf("handler panic: %v", v)
+				}
+			}()
+
+			return next(m)
+		}
+	}
+}
+
+// MessageKey identifies a message to detect duplicate deliveries,
+// it's the MsgId of messages, or FromUserName and CreateTime of events.
+func MessageKey(m wechat.Message) string {
+	if id := wechat.MessageId(m); id != 0 {
+		return strconv.FormatInt(id, 10)
+	}
+
+	header := m.Header()
+
+	return header.FromUserName + "#" + strconv.FormatInt(header.CreateTime, 10)
+}
+
+// IdempotencyStore caches the encoded replies of processed messages by
+// MessageKey, implement it on a shared store to dedupe across instances.
+type IdempotencyStore interface {
+	// Get returns the value of key, ok is false if key is not set or expired.
+	Get(key string) (value []byte, ok bool, err error)
+	// Set sets the value of key which expires after ttl.
+	Set(key string, value []byte, ttl time.Duration) error
+}
+
+// Dedupe replies the cached reply to messages processed in the last ttl, so
+// retries of WeChat get the same reply without running handlers again.
+// A retry arriving while the message is in process waits for its reply.
+func Dedupe(store IdempotencyStore, ttl time.Duration) Middleware {
+	var mutex sync.Mutex
+	inflight := make(map[string]chan struct{})
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(m wechat.Message) (wechat.Reply, error) {
+			key := MessageKey(m)
+
+			mutex.Lock()
+			done, processing := inflight[key]
+
+			if !processing {
+				done = make(chan struct{})
+				inflight[key] = done
+			}
+
+			mutex.Unlock()
+
+			if processing {
+				<-done
+			} else {
+				defer func() {
+					mutex.Lock()
+					delete(inflight, key)
+					mutex.Unlock()
+					close(done)
+				}()
+			}
+
+			value, ok, err := store.Get(key)
+
+			if err != nil {
+				log.WithError(err).WithField("key", key).Warn("get cached reply")
+			}
+
+			if ok {
+				reply, err := wechat.DecodeReply(value)
+
+				if err == nil {
+					log.WithField("key", key).Info("reply duplicate message from cache")
+					return reply, nil
+				}
+
+				log.WithError(err).WithField("key", key).Warn("decode cached reply")
+			}
+
+			reply, err := next(m)
+
+			// Failed messages are not cached, so that the retries run handlers again.
+			if err != nil {
+				return reply, err
+			}
+
+			value, err = wechat.EncodeReply(reply)
+
+			if err == nil {
+				err = store.Set(key, value, ttl)
+			}
+
+			if err != nil {
+				log.WithError(err).WithField("key", key).Warn("cache reply")
+			}
+
+			return reply, nil
+		}
+	}
+}
+
+// MemoryStore is an IdempotencyStore in memory holding up to capacity entries,
+// the least recently set ones are evicted first.
+type MemoryStore struct {
+	lru *cache.LRU
+}
+
+func NewMemoryStore(capacity int) *MemoryStore {
+	return &MemoryStore{cache.New(capacity)}
+}
+
+func (s *MemoryStore) Get(key string) ([]byte, bool, error) {
+	value, ok := s.lru.Get(key)
+	return value, ok, nil
+}
+
+func (s *MemoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.lru.Set(key, value, ttl)
+	return nil
+}
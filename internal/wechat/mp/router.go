@@ -0,0 +1,105 @@
+// Package mp routes inbound messages of an official account to handlers.
+package mp
+
+import (
+	"strings"
+
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// HandlerFunc handles an inbound message and returns the passive reply,
+// a nil reply means nothing to reply.
+type HandlerFunc func(m wechat.Message) (wechat.Reply, error)
+
+// Middleware wraps a handler, e.g. to log or recover from panics.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Router dispatches messages to the handler registered for the most specific
+// route: the event and its key first, then the event, then the message type.
+// Messages that match no route are handled by Fallback.
+type Router struct {
+	routes     map[string]HandlerFunc
+	middleware []Middleware
+	// Fallback handles unmatched messages, nothing is replied if it's nil.
+	Fallback HandlerFunc
+}
+
+func NewRouter() *Router {
+	return &Router{
+		routes: make(map[string]HandlerFunc),
+	}
+}
+
+func routeKey(parts ...string) string {
+	return strings.Join(parts, "\x00")
+}
+
+// Handle registers h for messages of msgType, e.g. `wechat.MsgTypeText`.
+func (r *Router) Handle(msgType string, h HandlerFunc) {
+	r.routes[routeKey(msgType)] = h
+}
+
+// HandleEvent registers h for events of event, e.g. `wechat.EventSubscribe`.
+func (r *Router) HandleEvent(event string, h HandlerFunc) {
+	r.routes[routeKey(wechat.MsgTypeEvent, strings.ToUpper(event))] = h
+}
+
+// HandleEventKey registers h for events of event with the EventKey key,
+// e.g. a CLICK menu.
+func (r *Router) HandleEventKey(event, key string, h HandlerFunc) {
+	r.routes[routeKey(wechat.MsgTypeEvent, strings.ToUpper(event), key)] = h
+}
+
+// HasEventKey reports whether a handler is registered for event with key.
+func (r *Router) HasEventKey(event, key string) bool {
+	_, ok := r.routes[routeKey(wechat.MsgTypeEvent, strings.ToUpper(event), key)]
+	return ok
+}
+
+// Use appends middleware to the chain, the first one is the outermost.
+func (r *Router) Use(middleware ...Middleware) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Dispatch handles m with the matched handler wrapped by the middleware chain.
+func (r *Router) Dispatch(m wechat.Message) (wechat.Reply, error) {
+	h := r.match(m)
+
+	for i := len(r.middleware) - 1; i >= 0; i-- {
+		h = r.middleware[i](h)
+	}
+
+	return h(m)
+}
+
+func (r *Router) match(m wechat.Message) HandlerFunc {
+	header := m.Header()
+
+	var keys []string
+
+	if header.MsgType == wechat.MsgTypeEvent {
+		event := strings.ToUpper(header.Event)
+
+		if keyed, ok := m.(wechat.KeyedEvent); ok {
+			keys = append(keys, routeKey(wechat.MsgTypeEvent, event, keyed.Key()))
+		}
+
+		keys = append(keys, routeKey(wechat.MsgTypeEvent, event))
+	}
+
+	keys = append(keys, routeKey(header.MsgType))
+
+	for _, key := range keys {
+		if h, ok := r.routes[key]; ok {
+			return h
+		}
+	}
+
+	if r.Fallback != nil {
+		return r.Fallback
+	}
+
+	return func(wechat.Message) (wechat.Reply, error) {
+		return nil, nil
+	}
+}
@@ -0,0 +1,300 @@
+package wechat
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+)
+
+// Types of passive replies.
+const (
+	ReplyTypeText                    = "text"
+	ReplyTypeImage                   = "image"
+	ReplyTypeVoice                   = "voice"
+	ReplyTypeVideo                   = "video"
+	ReplyTypeMusic                   = "music"
+	ReplyTypeNews                    = "news"
+	ReplyTypeTransferCustomerService = "transfer_customer_service"
+)
+
+// MaxReplyNewsArticles is the maximum number of articles of a news reply,
+// note that WeChat clients only show the first one nowadays.
+const MaxReplyNewsArticles = 8
+
+// Reply is a passive reply to an inbound message, see MarshalReply.
+type Reply interface {
+	MsgType() string
+	encodeBody(e *xml.Encoder) error
+}
+
+// TextReply replies a text message.
+type TextReply struct {
+	Content string
+}
+
+// ImageReply replies an image uploaded as media.
+type ImageReply struct {
+	MediaId string
+}
+
+// VoiceReply replies a voice uploaded as media.
+type VoiceReply struct {
+	MediaId string
+}
+
+// VideoReply replies a video uploaded as media.
+type VideoReply struct {
+	MediaId     string
+	Title       string
+	Description string
+}
+
+// MusicReply replies a music, ThumbMediaId is the media id of the cover.
+type MusicReply struct {
+	Title        string
+	Description  string
+	MusicURL     string
+	HQMusicURL   string
+	ThumbMediaId string
+}
+
+// NewsReply replies a news message of up to `MaxReplyNewsArticles` articles.
+type NewsReply struct {
+	Articles []NewsArticle
+}
+
+// NewsArticle is an article of NewsReply, the picture at PicURL is
+// 360x200 for the first article and 200x200 for the others.
+type NewsArticle struct {
+	Title       string
+	Description string
+	PicURL      string
+	URL         string
+}
+
+// TransferCustomerServiceReply transfers the message to the customer service,
+// to KfAccount if it's not empty.
+type TransferCustomerServiceReply struct {
+	KfAccount string
+}
+
+func (TextReply) MsgType() string                    { return ReplyTypeText }
+func (ImageReply) MsgType() string                   { return ReplyTypeImage }
+func (VoiceReply) MsgType() string                   { return ReplyTypeVoice }
+func (VideoReply) MsgType() string                   { return ReplyTypeVideo }
+func (MusicReply) MsgType() string                   { return ReplyTypeMusic }
+func (NewsReply) MsgType() string                    { return ReplyTypeNews }
+func (TransferCustomerServiceReply) MsgType() string { return ReplyTypeTransferCustomerService }
+
+// cdata is marshaled as a CDATA section, `]]>` in the value is split
+// into two sections by encoding/xml.
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+func encodeCDATA(e *xml.Encoder, name, value string) error {
+	return e.EncodeElement(cdata{value}, xml.StartElement{Name: xml.Name{Local: name}})
+}
+
+// encodeCDATAElements encodes an element of name containing pairs of names and values.
+func encodeCDATAElements(e *xml.Encoder, name string, pairs ...string) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if err := encodeCDATA(e, pairs[i], pairs[i+1]); err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func (r TextReply) encodeBody(e *xml.Encoder) error {
+	return encodeCDATA(e, "Content", r.Content)
+}
+
+func (r ImageReply) encodeBody(e *xml.Encoder) error {
+	return encodeCDATAElements(e, "Image", "MediaId", r.MediaId)
+}
+
+func (r VoiceReply) encodeBody(e *xml.Encoder) error {
+	return encodeCDATAElements(e, "Voice", "MediaId", r.MediaId)
+}
+
+func (r VideoReply) encodeBody(e *xml.Encoder) error {
+	return encodeCDATAElements(e, "Video", "MediaId", r.MediaId, "Title", r.Title, "Description", r.Description)
+}
+
+func (r MusicReply) encodeBody(e *xml.Encoder) error {
+	return encodeCDATAElements(e, "Music",
+		"Title", r.Title,
+		"Description", r.Description,
+		"MusicUrl", r.MusicURL,
+		"HQMusicUrl", r.HQMusicURL,
+		"ThumbMediaId", r.ThumbMediaId,
+	)
+}
+
+func (r NewsReply) encodeBody(e *xml.Encoder) error {
+	if len(r.Articles) == 0 || len(r.Articles) > MaxReplyNewsArticles {
+		return fmt.Errorf("a news reply has 1 to %d articles, got %d", MaxReplyNewsArticles, len(r.Articles))
+	}
+
+	if err := e.EncodeElement(len(r.Articles), xml.StartElement{Name: xml.Name{Local: "ArticleCount"}}); err != nil {
+		return err
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: "Articles"}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, a := range r.Articles {
+		err := encodeCDATAElements(e, "item",
+			"Title", a.Title,
+			"Description", a.Description,
+			"PicUrl", a.PicURL,
+			"Url", a.URL,
+		)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+func (r TransferCustomerServiceReply) encodeBody(e *xml.Encoder) error {
+	if r.KfAccount == "" {
+		return nil
+	}
+
+	return encodeCDATAElements(e, "TransInfo", "KfAccount", r.KfAccount)
+}
+
+// MarshalReply marshals r to the XML of a passive reply from the account `from`
+// to the follower `to`.
+func MarshalReply(r Reply, to, from string, createTime int64) ([]byte, error) {
+	var buf bytes.Buffer
+
+	e := xml.NewEncoder(&buf)
+	start := xml.StartElement{Name: xml.Name{Local: "xml"}}
+
+	if err := e.EncodeToken(start); err != nil {
+		return nil, err
+	}
+
+	if err := encodeCDATA(e, "ToUserName", to); err != nil {
+		return nil, err
+	}
+
+	if err := encodeCDATA(e, "FromUserName", from); err != nil {
+		return nil, err
+	}
+
+	if err := e.EncodeElement(createTime, xml.StartElement{Name: xml.Name{Local: "CreateTime"}}); err != nil {
+		return nil, err
+	}
+
+	if err := encodeCDATA(e, "MsgType", r.MsgType()); err != nil {
+		return nil, err
+	}
+
+	if err := r.encodeBody(e); err != nil {
+		return nil, err
+	}
+
+	if err := e.EncodeToken(start.End()); err != nil {
+		return nil, err
+	}
+
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// MarshalEncryptedReply marshals the envelope of an encrypted reply in safe mode.
+func MarshalEncryptedReply(encrypt, msgSignature, timestamp, nonce string) ([]byte, error) {
+	return xml.Marshal(struct {
+		XMLName      xml.Name `xml:"xml"`
+		Encrypt      cdata    `xml:"Encrypt"`
+		MsgSignature cdata    `xml:"MsgSignature"`
+		TimeStamp    string   `xml:"TimeStamp"`
+		Nonce        cdata    `xml:"Nonce"`
+	}{
+		Encrypt:      cdata{encrypt},
+		MsgSignature: cdata{msgSignature},
+		TimeStamp:    timestamp,
+		Nonce:        cdata{nonce},
+	})
+}
+
+type encodedReply struct {
+	Type  string          `json:"type"`
+	Reply json.RawMessage `json:"reply,omitempty"`
+}
+
+// EncodeReply encodes r to JSON with its type to be cached, see DecodeReply.
+// A nil reply is encoded as well.
+func EncodeReply(r Reply) ([]byte, error) {
+	if r == nil {
+		return json.Marshal(encodedReply{})
+	}
+
+	raw, err := json.Marshal(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encodedReply{Type: r.MsgType(), Reply: raw})
+}
+
+// DecodeReply decodes a reply encoded by EncodeReply.
+func DecodeReply(data []byte) (Reply, error) {
+	var encoded encodedReply
+
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, err
+	}
+
+	var r Reply
+
+	switch encoded.Type {
+	case "":
+		return nil, nil
+	case ReplyTypeText:
+		r = &TextReply{}
+	case ReplyTypeImage:
+		r = &ImageReply{}
+	case ReplyTypeVoice:
+		r = &VoiceReply{}
+	case ReplyTypeVideo:
+		r = &VideoReply{}
+	case ReplyTypeMusic:
+		r = &MusicReply{}
+	case ReplyTypeNews:
+		r = &NewsReply{}
+	case ReplyTypeTransferCustomerService:
+		r = &TransferCustomerServiceReply{}
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", encoded.Type)
+	}
+
+	if err := json.Unmarshal(encoded.Reply, r); err != nil {
+		return nil, err
+	}
+
+	// Return the value rather than the pointer, as handlers do.
+	return reflect.ValueOf(r).Elem().Interface().(Reply), nil
+}
@@ -0,0 +1,67 @@
+package wechat
+
+// TemplateValue is the value of a field of a template message.
+type TemplateValue struct {
+	Value string `json:"value"`
+	Color string `json:"color,omitempty"`
+}
+
+// TemplateData holds the fields of a template message by their keys,
+// e.g. `first`, `keyword1` and `remark`.
+type TemplateData map[string]TemplateValue
+
+// NewTemplateData returns empty data to be built by Set and SetColor.
+func NewTemplateData() TemplateData {
+	return make(TemplateData)
+}
+
+// Set sets the value of the field of key.
+func (d TemplateData) Set(key, value string) TemplateData {
+	return d.SetColor(key, value, "")
+}
+
+// SetColor sets the value of the field of key in color, e.g. `#173177`.
+func (d TemplateData) SetColor(key, value, color string) TemplateData {
+	d[key] = TemplateValue{value, color}
+	return d
+}
+
+// TemplateMiniProgram links a template message to a page of a mini program.
+type TemplateMiniProgram struct {
+	AppId    string `json:"appid"`
+	PagePath string `json:"pagepath,omitempty"`
+}
+
+// TemplateMessage is a template message to a follower.
+type TemplateMessage struct {
+	ToUser      string               `json:"touser"`
+	TemplateId  string               `json:"template_id"`
+	URL         string               `json:"url,omitempty"`
+	MiniProgram *TemplateMiniProgram `json:"miniprogram,omitempty"`
+	// ClientMsgId prevents the message from being sent twice.
+	ClientMsgId string       `json:"client_msg_id,omitempty"`
+	Data        TemplateData `json:"data"`
+}
+
+// SendTemplateMessage sends the template message, the result is reported later
+// by a TemplateSendJobFinishEvent with msgId.
+func SendTemplateMessage(client *Client, message *TemplateMessage) (msgId int64, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/message/template/send"
+
+	var result struct {
+		WechatGlobalError
+		MsgId int64 `json:"msgid"`
+	}
+
+	if err = client.Post(apiURL, message, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	msgId = result.MsgId
+	return
+}
@@ -0,0 +1,372 @@
+package wechat
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Limits of the batch APIs of followers and tags.
+const (
+	MaxBatchGetFollowers = 100
+	MaxBatchTagging      = 50
+)
+
+// FollowerInfo is the information of a follower, Subscribe is 0 if the user
+// doesn't follow the account, and the other fields are empty then.
+type FollowerInfo struct {
+	Subscribe      int    `json:"subscribe"`
+	OpenId         string `json:"openid"`
+	Language       string `json:"language"`
+	SubscribeTime  int64  `json:"subscribe_time"`
+	UnionId        string `json:"unionid,omitempty"`
+	Remark         string `json:"remark"`
+	GroupId        int    `json:"groupid"`
+	TagIdList      []int  `json:"tagid_list"`
+	SubscribeScene string `json:"subscribe_scene"`
+	QrScene        int    `json:"qr_scene"`
+	QrSceneStr     string `json:"qr_scene_str"`
+}
+
+// FollowerList is a page of the OpenIDs of followers, pass NextOpenId to
+// GetFollowers to get the next page. Total is -1 if it's unknown.
+type FollowerList struct {
+	Total      int
+	OpenIds    []string
+	NextOpenId string
+}
+
+// GetFollowers returns up to 10000 followers after nextOpenId,
+// the first page is returned if nextOpenId is empty.
+func GetFollowers(client *Client, nextOpenId string) (list FollowerList, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/user/get"
+
+	var result struct {
+		WechatGlobalError
+		Total int `json:"total"`
+		Count int `json:"count"`
+		Data  struct {
+			OpenId []string `json:"openid"`
+		} `json:"data"`
+		NextOpenId string `json:"next_openid"`
+	}
+
+	var querystring string
+
+	if nextOpenId != "" {
+		querystring = "next_openid=" + url.QueryEscape(nextOpenId)
+	}
+
+	if err = client.Get(apiURL, querystring, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	list.Total = result.Total
+	list.OpenIds = result.Data.OpenId
+
+	// The last page has the last OpenID as next_openid.
+	if result.Count > 0 {
+		list.NextOpenId = result.NextOpenId
+	}
+
+	return
+}
+
+// GetAllFollowers returns the OpenIDs of all followers.
+func GetAllFollowers(client *Client) (openIds []string, err error) {
+	var next string
+
+	for {
+		list, err := GetFollowers(client, next)
+
+		if err != nil {
+			return nil, err
+		}
+
+		openIds = append(openIds, list.OpenIds...)
+
+		if list.NextOpenId == "" || len(openIds) >= list.Total {
+			return openIds, nil
+		}
+
+		next = list.NextOpenId
+	}
+}
+
+// GetTagFollowers returns up to 10000 followers of the tag after nextOpenId,
+// like GetFollowers.
+func GetTagFollowers(client *Client, tagId int, nextOpenId string) (list FollowerList, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/user/tag/get"
+
+	var result struct {
+		WechatGlobalError
+		Count int `json:"count"`
+		Data  struct {
+			OpenId []string `json:"openid"`
+		} `json:"data"`
+		NextOpenId string `json:"next_openid"`
+	}
+
+	var data = struct {
+		TagId      int    `json:"tagid"`
+		NextOpenId string `json:"next_openid"`
+	}{tagId, nextOpenId}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	list.Total = -1
+	list.OpenIds = result.Data.OpenId
+
+	if result.Count > 0 {
+		list.NextOpenId = result.NextOpenId
+	}
+
+	return
+}
+
+// GetAllTagFollowers returns the OpenIDs of all followers of the tag.
+func GetAllTagFollowers(client *Client, tagId int) (openIds []string, err error) {
+	var next string
+
+	for {
+		list, err := GetTagFollowers(client, tagId, next)
+
+		if err != nil {
+			return nil, err
+		}
+
+		openIds = append(openIds, list.OpenIds...)
+
+		if list.NextOpenId == "" {
+			return openIds, nil
+		}
+
+		next = list.NextOpenId
+	}
+}
+
+// GetFollowerInfo returns the information of the follower, lang is one of
+// zh_CN, zh_TW and en.
+func GetFollowerInfo(client *Client, openId, lang string) (info *FollowerInfo, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/user/info"
+
+	var result struct {
+		WechatGlobalError
+		FollowerInfo
+	}
+
+	querystring := url.Values{"openid": {openId}, "lang": {lang}}.Encode()
+
+	if err = client.Get(apiURL, querystring, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	info = &result.FollowerInfo
+	return
+}
+
+// BatchGetFollowerInfo returns the information of up to `MaxBatchGetFollowers` followers.
+func BatchGetFollowerInfo(client *Client, openIds []string, lang string) (infos []FollowerInfo, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/user/info/batchget"
+
+	if len(openIds) == 0 || len(openIds) > MaxBatchGetFollowers {
+		err = fmt.Errorf("get information of 1 to %d followers at a time, got %d", MaxBatchGetFollowers, len(openIds))
+		return
+	}
+
+	type user struct {
+		OpenId string `json:"openid"`
+		Lang   string `json:"lang"`
+	}
+
+	var data = struct {
+		UserList []user `json:"user_list"`
+	}{}
+
+	for _, openId := range openIds {
+		data.UserList = append(data.UserList, user{openId, lang})
+	}
+
+	var result struct {
+		WechatGlobalError
+		UserInfoList []FollowerInfo `json:"user_info_list"`
+	}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	infos = result.UserInfoList
+	return
+}
+
+// GetAllTags returns all tags of the account.
+func GetAllTags(client *Client) (tags []Tag, err error) {
+	return fetchAllTags(client)
+}
+
+// CreateTag creates a tag of name, which is up to 30 characters.
+func CreateTag(client *Client, name string) (tag Tag, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/tags/create"
+
+	var result struct {
+		WechatGlobalError
+		Tag Tag `json:"tag"`
+	}
+
+	var data = struct {
+		Tag struct {
+			Name string `json:"name"`
+		} `json:"tag"`
+	}{}
+
+	data.Tag.Name = name
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	tag = result.Tag
+	return
+}
+
+// UpdateTag renames the tag.
+func UpdateTag(client *Client, id int, name string) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/tags/update"
+
+	var result WechatGlobalError
+
+	var data = struct {
+		Tag struct {
+			Id   int    `json:"id"`
+			Name string `json:"name"`
+		} `json:"tag"`
+	}{}
+
+	data.Tag.Id = id
+	data.Tag.Name = name
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// DeleteTag deletes the tag, the followers of it are untagged.
+func DeleteTag(client *Client, id int) (err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/tags/delete"
+
+	var result WechatGlobalError
+
+	var data = struct {
+		Tag struct {
+			Id int `json:"id"`
+		} `json:"tag"`
+	}{}
+
+	data.Tag.Id = id
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// BatchTagging tags up to `MaxBatchTagging` followers.
+func BatchTagging(client *Client, tagId int, openIds []string) (err error) {
+	return batchTag(client, "https://api.weixin.qq.com/cgi-bin/tags/members/batchtagging", tagId, openIds)
+}
+
+// BatchUntagging untags up to `MaxBatchTagging` followers.
+func BatchUntagging(client *Client, tagId int, openIds []string) (err error) {
+	return batchTag(client, "https://api.weixin.qq.com/cgi-bin/tags/members/batchuntagging", tagId, openIds)
+}
+
+func batchTag(client *Client, apiURL string, tagId int, openIds []string) (err error) {
+	if len(openIds) == 0 || len(openIds) > MaxBatchTagging {
+		err = fmt.Errorf("tag 1 to %d followers at a time, got %d", MaxBatchTagging, len(openIds))
+		return
+	}
+
+	var result WechatGlobalError
+
+	var data = struct {
+		OpenIdList []string `json:"openid_list"`
+		TagId      int      `json:"tagid"`
+	}{openIds, tagId}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result
+		return
+	}
+
+	return
+}
+
+// GetTagIdList returns the ids of the tags of the follower.
+func GetTagIdList(client *Client, openId string) (tagIds []int, err error) {
+	apiURL := "https://api.weixin.qq.com/cgi-bin/tags/getidlist"
+
+	var result struct {
+		WechatGlobalError
+		TagIdList []int `json:"tagid_list"`
+	}
+
+	var data = struct {
+		OpenId string `json:"openid"`
+	}{openId}
+
+	if err = client.Post(apiURL, &data, &result); err != nil {
+		return
+	}
+
+	if result.ErrCode != 0 {
+		err = &result.WechatGlobalError
+		return
+	}
+
+	tagIds = result.TagIdList
+	return
+}
@@ -37,10 +37,23 @@ type Material struct {
 	Url        string `json:"url"`
 }
 
+// Limits of an article material accepted by WeChat, lengths are counted in characters.
+const (
+	MaxArticleTitleLength   = 64
+	MaxArticleDigestLength  = 120
+	MaxArticleContentLength = 20000
+	MaxArticleContentSize   = 1 << 20
+)
+
+// MaxNewsArticles is the maximum number of articles in a news message.
+const MaxNewsArticles = 8
+
 type ArticleMaterial struct {
-	ThumbMediaId string `json:"thumb_media_id"`
-	Title        string `json:"title"`
-	Content      string `json:"content"`
-	Digest       string `json:"digest"`
-	ShowCoverPic int    `json:"show_cover_pic"`
+	ThumbMediaId     string `json:"thumb_media_id"`
+	Title            string `json:"title"`
+	Author           string `json:"author,omitempty"`
+	Content          string `json:"content"`
+	ContentSourceURL string `json:"content_source_url,omitempty"`
+	Digest           string `json:"digest"`
+	ShowCoverPic     int    `json:"show_cover_pic"`
 }
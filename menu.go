@@ -0,0 +1,105 @@
+package progressbar201X
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/apex/log"
+	"gopkg.in/yaml.v2"
+
+	"github.com/sqrthree/progressbar201X/internal/controller"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// MenuDefinition is the declarative definition of the menus of the account,
+// see menu.example.yml.
+type MenuDefinition struct {
+	Menu wechat.Menu `yaml:"menu"`
+	// Conditional lists the personalized menus, each of which has a match rule.
+	Conditional []wechat.Menu `yaml:"conditional"`
+}
+
+// LoadMenuDefinition reads the definition from the YAML file at path and validates it.
+func LoadMenuDefinition(path string) (*MenuDefinition, error) {
+	conts, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var def MenuDefinition
+
+	if err := yaml.UnmarshalStrict(conts, &def); err != nil {
+		return nil, err
+	}
+
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &def, nil
+}
+
+// Validate checks the menus against the limits of WeChat, and checks that every
+// CLICK key is handled by the router.
+func (def *MenuDefinition) Validate() error {
+	menus := append([]wechat.Menu{def.Menu}, def.Conditional...)
+
+	for i, menu := range menus {
+		if err := menu.Validate(); err != nil {
+			return err
+		}
+
+		if i > 0 && menu.MatchRule == nil {
+			return fmt.Errorf("personalized menu #%d has no match rule", i)
+		}
+
+		for _, key := range menu.ClickKeys() {
+			if !controller.Router.HasEventKey(wechat.EventClick, key) {
+				return fmt.Errorf("no handler is registered for the CLICK key %q", key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SyncMenu replaces the menus of the account with def. The custom menu is
+// overwritten in place rather than deleted first, so the account keeps its
+// menus if the new one is rejected.
+func SyncMenu(def *MenuDefinition) error {
+	_, conditional, err := wechat.GetMenu(wechatClient)
+
+	if err != nil {
+		return err
+	}
+
+	if err := wechat.CreateMenu(wechatClient, &def.Menu); err != nil {
+		return err
+	}
+
+	for _, menu := range conditional {
+		if err := wechat.DeleteConditionalMenu(wechatClient, menu.MenuId); err != nil {
+			return err
+		}
+
+		log.WithField("menuid", menu.MenuId).Info("delete personalized menu")
+	}
+
+	for i := range def.Conditional {
+		menuId, err := wechat.AddConditionalMenu(wechatClient, &def.Conditional[i])
+
+		if err != nil {
+			return err
+		}
+
+		log.WithField("menuid", menuId).Info("add personalized menu")
+	}
+
+	return nil
+}
+
+// GetMenu returns the menus of the account, menu is nil if there isn't any.
+func GetMenu() (menu *wechat.Menu, conditional []wechat.Menu, err error) {
+	return wechat.GetMenu(wechatClient)
+}
@@ -0,0 +1,287 @@
+package progressbar201X
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/mailer"
+	"github.com/sqrthree/progressbar201X/internal/publish"
+	"github.com/sqrthree/progressbar201X/internal/store"
+)
+
+// PublisherEmail is the name of the publisher of the newsletter.
+const PublisherEmail = "email"
+
+// ChannelEmail is the channel of deliveries of the newsletter, recipients are the addresses.
+const ChannelEmail = "email"
+
+var newsletterTemplate = template.Must(template.New("newsletter").Parse(`<!DOCTYPE html>
+<html lang="{{.Locale}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body style="margin: 0 auto; padding: 20px; max-width: 640px;">
+{{.Content}}
+<hr style="margin-top: 40px; border: none; border-top: 1px solid #e5e5e5;">
+<p style="color: #999; font-size: 12px; text-align: center;">{{.Footer}} <a href="{{.UnsubscribeURL}}" style="color: #999;">{{.Unsubscribe}}</a></p>
+</body>
+</html>`))
+
+type newsletterPage struct {
+	Locale         string
+	Title          string
+	Content        template.HTML
+	Footer         string
+	Unsubscribe    string
+	UnsubscribeURL string
+}
+
+// newsletterPublisher mails the article to the subscribers of `Config.Mail.Subscribers`.
+type newsletterPublisher struct {
+	dialer *mailer.Dialer
+	from   *mail.Address
+}
+
+func newNewsletterPublisher() (*newsletterPublisher, error) {
+	conf := Config.Mail
+
+	if conf.Host == "" {
+		return nil, errors.New("the SMTP server is not set")
+	}
+
+	if conf.Secret == "" || Config.Server.BaseURL == "" {
+		return nil, errors.New("links to unsubscribe need the secret and the base URL of the server")
+	}
+
+	from, err := mail.ParseAddress(conf.From)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %v", conf.From, err)
+	}
+
+	return &newsletterPublisher{
+		dialer: &mailer.Dialer{
+			Host:     conf.Host,
+			Port:     conf.Port,
+			Username: conf.Username,
+			Password: conf.Password,
+			NoTLS:    conf.NoTLS,
+			Timeout:  time.Duration(conf.Timeout) * time.Second,
+		},
+		from: from,
+	}, nil
+}
+
+func (n *newsletterPublisher) Name() string {
+	return PublisherEmail
+}
+
+// Prepare checks the subscribers, the ref is the date.
+func (n *newsletterPublisher) Prepare(p *publish.Post) (string, error) {
+	if _, err := mailer.ReadSubscribers(Config.Mail.Subscribers); err != nil {
+		return "", err
+	}
+
+	return p.Date(), nil
+}
+
+// Preview mails the article to the address only.
+func (n *newsletterPublisher) Preview(p *publish.Post, ref, to string) error {
+	address, err := mail.ParseAddress(to)
+
+	if err != nil {
+		return err
+	}
+
+	conn, err := n.dialer.Dial()
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	message, err := n.message(p, *address)
+
+	if err != nil {
+		return err
+	}
+
+	return conn.Send(message)
+}
+
+// Publish mails the article to the subscribers one by one at `Config.Mail.Rate`, and
+// records the delivery to each of them. Subscribers who have got the mail of the day,
+// or have unsubscribed, are skipped. It stops at once if the SMTP server can't be
+// dialed.
+func (n *newsletterPublisher) Publish(p *publish.Post, ref, _ string) (string, error) {
+	subscribers, err := mailer.ReadSubscribers(Config.Mail.Subscribers)
+
+	if err != nil {
+		return "", err
+	}
+
+	s := store.Default()
+
+	var (
+		conn     *mailer.Conn
+		interval time.Duration
+		last     time.Time
+		failed   int
+	)
+
+	if Config.Mail.Rate > 0 {
+		interval = time.Minute / time.Duration(Config.Mail.Rate)
+	}
+
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	for _, address := range subscribers {
+		recipient := strings.ToLower(address.Address)
+
+		unsubscribed, err := s.NewsletterUnsubscribed(recipient)
+
+		if err != nil {
+			return "", err
+		}
+
+		d, ok, err := s.DeliveryOf(p.Date(), ChannelEmail, recipient)
+
+		if err != nil {
+			return "", err
+		}
+
+		if unsubscribed || ok && d.Status == store.DeliverySucceeded {
+			continue
+		}
+
+		if wait := interval - time.Since(last); wait > 0 {
+			time.Sleep(wait)
+		}
+
+		last = time.Now()
+
+		d = store.Delivery{
+			Date:      p.Date(),
+			Channel:   ChannelEmail,
+			Recipient: recipient,
+			Status:    store.DeliverySucceeded,
+		}
+
+		// The connection is dialed again after an error, it may be broken. Failures
+		// of dialing aren't of the recipient, the pipeline retries them later.
+		if conn == nil {
+			if conn, err = n.dialer.Dial(); err != nil {
+				return "", err
+			}
+		}
+
+		err = n.send(conn, p, address)
+		d.At = time.Now()
+
+		if err != nil {
+			log.WithError(err).WithField("email", recipient).Error("send newsletter")
+
+			d.Status = store.DeliveryFailed
+			d.Error = err.Error()
+			failed++
+
+			conn.Close()
+			conn = nil
+		}
+
+		if err := s.AddDelivery(d); err != nil {
+			return "", err
+		}
+	}
+
+	if failed > 0 {
+		return "", fmt.Errorf("%d mails of the newsletter failed", failed)
+	}
+
+	return p.Date(), nil
+}
+
+// Status returns the counts of deliveries to subscribers by status, id is the date.
+func (n *newsletterPublisher) Status(id string) (string, error) {
+	return deliverySummary(id, ChannelEmail)
+}
+
+func (n *newsletterPublisher) send(conn *mailer.Conn, p *publish.Post, to mail.Address) error {
+	message, err := n.message(p, to)
+
+	if err != nil {
+		return err
+	}
+
+	return conn.Send(message)
+}
+
+// message returns the mail of p to the address, with the link to unsubscribe.
+func (n *newsletterPublisher) message(p *publish.Post, to mail.Address) (*mailer.Message, error) {
+	a := p.Article
+	locale := i18n.Match(a.Data.Locale)
+	unsubscribeURL := UnsubscribeURL(to.Address)
+
+	text, err := a.Render(article.TextRenderer{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	footer := i18n.T(locale, "newsletter.footer")
+	text += "\n--\n" + footer + " " + unsubscribeURL + "\n"
+
+	var html bytes.Buffer
+
+	err = newsletterTemplate.Execute(&html, newsletterPage{
+		Locale:         locale,
+		Title:          a.Title,
+		Content:        template.HTML(a.Content),
+		Footer:         footer,
+		Unsubscribe:    i18n.T(locale, "newsletter.unsubscribe"),
+		UnsubscribeURL: unsubscribeURL,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &mailer.Message{
+		From:    *n.from,
+		To:      to,
+		Subject: a.Title,
+		Text:    text,
+		HTML:    html.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+// UnsubscribeURL returns the link of the address to unsubscribe from the newsletter.
+func UnsubscribeURL(address string) string {
+	query := url.Values{}
+	query.Set("email", address)
+	query.Set("token", mailer.Token(Config.Mail.Secret, address))
+
+	return strings.TrimSuffix(Config.Server.BaseURL, "/") + "/unsubscribe?" + query.Encode()
+}
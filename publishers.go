@@ -0,0 +1,252 @@
+package progressbar201X
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/publish"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// PublisherWechat is the name of the publisher of the WeChat account.
+const PublisherWechat = "wechat"
+
+// massSendTagId is the tag of the followers of mass sends.
+const massSendTagId = 2
+
+// massPendingId is the id of a mass send whose result is unknown.
+const massPendingId = "mass:pending"
+
+// templateRefPrefix prefixes the ids of broadcasts by template messages, followed by the date.
+const templateRefPrefix = "template:"
+
+// NewPipeline returns the pipeline of the publishers of `Config.Publish`.
+func NewPipeline() (*publish.Pipeline, error) {
+	names := Config.Publish.Publishers
+
+	if len(names) == 0 {
+		names = []string{PublisherWechat}
+	}
+
+	pl := &publish.Pipeline{
+		Attempts: Config.Publish.Attempts,
+		Backoff:  time.Duration(Config.Publish.Backoff) * time.Second,
+		Store:    store.Default(),
+	}
+
+	for _, name := range names {
+		p, err := NewPublisher(name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		pl.Publishers = append(pl.Publishers, p)
+	}
+
+	return pl, nil
+}
+
+// NewPost returns the post of the year progress at now.
+func NewPost(now time.Time) (*publish.Post, error) {
+	progress, err := GetProgressOfCurrentYear()
+
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := NewArticle(now.Year(), progress)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &publish.Post{Time: now, Article: a}, nil
+}
+
+// PreviewPost prepares p by the publisher of name and sends it to the recipient only.
+func PreviewPost(name string, p *publish.Post, to string) error {
+	publisher, err := NewPublisher(name)
+
+	if err != nil {
+		return err
+	}
+
+	ref, err := publisher.Prepare(p)
+
+	if err != nil {
+		return err
+	}
+
+	return publisher.Preview(p, ref, to)
+}
+
+// NewPublisher returns the publisher of name.
+func NewPublisher(name string) (publish.Publisher, error) {
+	switch name {
+	case PublisherWechat:
+		return wechatPublisher{}, nil
+	case PublisherTelegram:
+		return newTelegramPublisher()
+	case PublisherWebhook:
+		return newWebhookPublisher()
+	case PublisherEmail:
+		return newNewsletterPublisher()
+	default:
+		return nil, fmt.Errorf("unknown publisher %q", name)
+	}
+}
+
+// wechatPublisher publishes posts to the followers of the account by mass send,
+// or template messages, see `Config.Wechat.Template.Mode`.
+type wechatPublisher struct{}
+
+func (wechatPublisher) Name() string {
+	return PublisherWechat
+}
+
+// Prepare uploads the bundle of the post, there is nothing to upload in template mode.
+func (wechatPublisher) Prepare(p *publish.Post) (string, error) {
+	if Config.Wechat.Template.Mode == BroadcastTemplate {
+		return "", nil
+	}
+
+	materials, err := NewBundle(p.Time, p.Article)
+
+	if err != nil {
+		return "", err
+	}
+
+	return UploadBundle(materials)
+}
+
+func (wechatPublisher) Preview(p *publish.Post, ref, to string) error {
+	if ref == "" {
+		message, err := NewTemplateMessage(NewTemplateContext(p.Time, p.Article), to)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = wechat.SendTemplateMessage(wechatClient, message)
+		return err
+	}
+
+	return wechat.PreviewNews(wechatClient, to, ref)
+}
+
+// Publish sends the bundle by mass send, or template messages in template mode, or
+// if the mass send is rejected in fallback mode. The mass send is never sent again
+// once it's accepted, or may have been accepted, or the fallback has started, which
+// is told by last.
+func (w wechatPublisher) Publish(p *publish.Post, ref, last string) (string, error) {
+	switch {
+	case ref == "" || strings.HasPrefix(last, templateRefPrefix):
+		return w.sendTemplateMessages(p)
+	case last == massPendingId:
+		return last, publish.Permanent(errors.New("the mass send may have been accepted, check the account before publishing again"))
+	case last != "":
+		return last, nil
+	}
+
+	// The access token is fetched first, so that its errors are retried rather than
+	// taken for the errors of the mass send.
+	if _, err := wechatClient.Token(); err != nil {
+		return "", err
+	}
+
+	// The mass send is recorded as pending before it's sent, so it's never sent
+	// again if the process crashes or panics before the result is recorded.
+	pending := store.Publication{
+		Date:      p.Date(),
+		Publisher: PublisherWechat,
+		Ref:       ref,
+		Id:        massPendingId,
+		Status:    store.PublicationPending,
+		At:        time.Now(),
+	}
+
+	if err := store.Default().AddPublication(pending); err != nil {
+		return "", err
+	}
+
+	msgId, err := wechat.SendMassNews(wechatClient, ref, massSendTagId)
+
+	if err != nil {
+		// Only an error returned by WeChat tells the mass send is rejected, the
+		// request may have been accepted if it fails otherwise, e.g. a timeout.
+		if _, ok := err.(*wechat.WechatGlobalError); !ok {
+			return massPendingId, publish.Permanent(err)
+		}
+
+		if Config.Wechat.Template.Mode == BroadcastFallback {
+			return w.sendTemplateMessages(p)
+		}
+
+		return "", err
+	}
+
+	return strconv.FormatInt(msgId, 10), nil
+}
+
+// sendTemplateMessages sends the template messages, the id is returned with errors
+// as well, so that the retries resume the template messages.
+func (wechatPublisher) sendTemplateMessages(p *publish.Post) (string, error) {
+	id := templateRefPrefix + p.Date()
+
+	_, failed, err := SendTemplateMessages(p.Time, p.Article)
+
+	if err != nil {
+		return id, err
+	}
+
+	if failed > 0 {
+		return id, fmt.Errorf("%d template messages failed", failed)
+	}
+
+	return id, nil
+}
+
+// Status returns the status of the mass send, or counts of template messages by status.
+func (wechatPublisher) Status(id string) (string, error) {
+	if id == massPendingId {
+		return "unknown", nil
+	}
+
+	if strings.HasPrefix(id, templateRefPrefix) {
+		return deliverySummary(strings.TrimPrefix(id, templateRefPrefix), ChannelTemplate)
+	}
+
+	msgId, err := strconv.ParseInt(id, 10, 64)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid id of mass send %q", id)
+	}
+
+	return wechat.GetMassStatus(wechatClient, msgId)
+}
+
+// deliverySummary returns the counts of deliveries through the channel of the date by status.
+func deliverySummary(date, channel string) (string, error) {
+	deliveries, err := Deliveries(date, channel)
+
+	if err != nil {
+		return "", err
+	}
+
+	counts := map[string]int{}
+
+	for _, d := range deliveries {
+		counts[d.Status]++
+	}
+
+	return fmt.Sprintf("%s: %d, %s: %d, %s: %d",
+		store.DeliverySucceeded, counts[store.DeliverySucceeded],
+		store.DeliveryPending, counts[store.DeliveryPending],
+		store.DeliveryFailed, counts[store.DeliveryFailed]), nil
+}
@@ -0,0 +1,13 @@
+package progressbar201X
+
+import (
+	"github.com/sqrthree/progressbar201X/internal/controller"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// DeliverReminders delivers the due reminders of followers by customer service messages.
+func DeliverReminders() {
+	controller.DeliverReminders(func(openId, content string) error {
+		return SendCustomMessage(openId, wechat.CustomText{Content: content})
+	})
+}
@@ -16,4 +16,17 @@ type route struct {
 var Routes = []route{
 	{"/", "GET", controller.Pong},
 	{"/", "POST", controller.HandleEvents},
+	{"/preview", "GET", controller.Preview},
+	{"/cover.png", "GET", controller.Cover},
+	{"/feed.xml", "GET", controller.RSSFeed},
+	{"/atom.xml", "GET", controller.AtomFeed},
+	{"/feed.json", "GET", controller.JSONFeed},
+	{"/api/progress", "GET", controller.Progress},
+	{"/api/progress", "OPTIONS", controller.Preflight},
+	{"/api/openapi.yaml", "GET", controller.OpenAPI},
+	// Badges of periods, e.g. /badge/year.svg and /badge/month.png.
+	{controller.BadgePrefix, "GET", controller.Badge},
+	{"/telegram", "POST", controller.TelegramWebhook},
+	{"/unsubscribe", "GET", controller.Unsubscribe},
+	{"/unsubscribe", "POST", controller.Unsubscribe},
 }
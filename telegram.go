@@ -0,0 +1,155 @@
+package progressbar201X
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/cover"
+	"github.com/sqrthree/progressbar201X/internal/publish"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/telegram"
+)
+
+// PublisherTelegram is the name of the publisher of the channels of Telegram.
+const PublisherTelegram = "telegram"
+
+// ChannelTelegram is the channel of deliveries to the channels of Telegram.
+const ChannelTelegram = "telegram"
+
+// Refs of prepared posts of Telegram, see `Config.Telegram.Photo`.
+const (
+	telegramText  = "text"
+	telegramPhoto = "photo"
+)
+
+// telegramPublisher posts the title and the digest, or the cover with them as the
+// caption, to `Config.Telegram.Channels`.
+type telegramPublisher struct {
+	client *telegram.Client
+}
+
+func newTelegramPublisher() (*telegramPublisher, error) {
+	if Config.Telegram.Token == "" {
+		return nil, errors.New("the token of the bot of Telegram is not set")
+	}
+
+	if len(Config.Telegram.Channels) == 0 {
+		return nil, errors.New("no channels of Telegram to publish to")
+	}
+
+	return &telegramPublisher{NewTelegramClient()}, nil
+}
+
+// NewTelegramClient returns the client of the bot of `Config.Telegram`.
+func NewTelegramClient() *telegram.Client {
+	return telegram.NewClient(Config.Telegram.Token, Config.Telegram.BaseURL, time.Duration(Config.Telegram.Timeout)*time.Second)
+}
+
+// SetTelegramWebhook points the webhook of the bot to `/telegram` of `Config.Server.BaseURL`.
+func SetTelegramWebhook() error {
+	if Config.Server.BaseURL == "" {
+		return errors.New("the base URL of the server is not set")
+	}
+
+	// The webhook rejects all updates without the secret.
+	if Config.Telegram.Secret == "" {
+		return errors.New("the secret of the webhook of Telegram is not set")
+	}
+
+	url := strings.TrimSuffix(Config.Server.BaseURL, "/") + "/telegram"
+
+	return NewTelegramClient().SetWebhook(url, Config.Telegram.Secret)
+}
+
+func (t *telegramPublisher) Name() string {
+	return PublisherTelegram
+}
+
+func (t *telegramPublisher) Prepare(p *publish.Post) (string, error) {
+	if Config.Telegram.Photo {
+		return telegramPhoto, nil
+	}
+
+	return telegramText, nil
+}
+
+func (t *telegramPublisher) Preview(p *publish.Post, ref, to string) error {
+	_, err := t.send(p, ref, to)
+	return err
+}
+
+// Publish posts to the channels one by one, and records the delivery to each of them.
+// Channels which have got the post of the day are skipped, so it's safe to retry.
+func (t *telegramPublisher) Publish(p *publish.Post, ref, _ string) (string, error) {
+	s := store.Default()
+	failed := 0
+
+	for _, channel := range Config.Telegram.Channels {
+		d, ok, err := s.DeliveryOf(p.Date(), ChannelTelegram, channel)
+
+		if err != nil {
+			return "", err
+		}
+
+		if ok && d.Status == store.DeliverySucceeded {
+			continue
+		}
+
+		d = store.Delivery{
+			Date:      p.Date(),
+			Channel:   ChannelTelegram,
+			Recipient: channel,
+			Status:    store.DeliverySucceeded,
+			At:        time.Now(),
+		}
+
+		if _, err := t.send(p, ref, channel); err != nil {
+			log.WithError(err).WithField("channel", channel).Error("post to telegram")
+
+			d.Status = store.DeliveryFailed
+			d.Error = err.Error()
+			failed++
+		}
+
+		if err := s.AddDelivery(d); err != nil {
+			return "", err
+		}
+	}
+
+	if failed > 0 {
+		return "", fmt.Errorf("%d of %d channels of Telegram failed", failed, len(Config.Telegram.Channels))
+	}
+
+	return p.Date(), nil
+}
+
+// Status returns the counts of deliveries to channels by status, id is the date.
+func (t *telegramPublisher) Status(id string) (string, error) {
+	return deliverySummary(id, ChannelTelegram)
+}
+
+func (t *telegramPublisher) send(p *publish.Post, ref, chatId string) (telegram.Message, error) {
+	text := p.Article.Title
+
+	if p.Article.Digest != "" {
+		text += "\n\n" + p.Article.Digest
+	}
+
+	if ref != telegramPhoto {
+		return t.client.SendMessage(chatId, text)
+	}
+
+	var buf bytes.Buffer
+
+	if err := cover.WritePNG(&buf, p.Article.Data.Progress, cover.Width, cover.Height, cover.DefaultStyle); err != nil {
+		return telegram.Message{}, err
+	}
+
+	return t.client.SendPhoto(chatId, "cover.png", buf.Bytes(), text)
+}
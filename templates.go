@@ -0,0 +1,198 @@
+package progressbar201X
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/apex/log"
+
+	"github.com/sqrthree/progressbar201X/internal/article"
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/i18n"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/wechat"
+)
+
+// Modes of broadcasts, see `Config.Wechat.Template.Mode`.
+const (
+	BroadcastMass     = "mass"
+	BroadcastFallback = "fallback"
+	BroadcastTemplate = "template"
+)
+
+// ChannelTemplate is the channel of deliveries of template messages.
+const ChannelTemplate = "template"
+
+// TemplateContext is the data of the fields of template messages.
+type TemplateContext struct {
+	Title  string
+	Digest string
+	Year   int
+	// Percent is the number of the progress, e.g. 50, and Progress is formatted, e.g. 50%.
+	Percent  string
+	Progress string
+	Bar      string
+	Date     string
+	BaseURL  string
+}
+
+// NewTemplateContext returns the context of a, which is broadcast at now.
+func NewTemplateContext(now time.Time, a *article.Article) TemplateContext {
+	return TemplateContext{
+		Title:    a.Title,
+		Digest:   a.Digest,
+		Year:     a.Data.Year,
+		Percent:  strconv.FormatFloat(a.Data.Progress, 'f', -1, 64),
+		Progress: i18n.FormatPercent(Config.App.Locale, a.Data.Progress),
+		Bar:      a.Data.Bar,
+		Date:     store.DateKey(now),
+		BaseURL:  strings.TrimSuffix(Config.Server.BaseURL, "/"),
+	}
+}
+
+// NewTemplateMessage builds the template message to openId by `Config.Wechat.Template`.
+func NewTemplateMessage(ctx TemplateContext, openId string) (*wechat.TemplateMessage, error) {
+	conf := Config.Wechat.Template
+
+	if conf.TemplateId == "" {
+		return nil, errors.New("template id is not configured")
+	}
+
+	data := wechat.NewTemplateData()
+
+	for key, field := range conf.Fields {
+		value, err := executeTemplate(key, field, ctx)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data.SetColor(key, value, conf.Colors[key])
+	}
+
+	url, err := executeTemplate("url", conf.URL, ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(ctx.Date + "#" + openId))
+
+	return &wechat.TemplateMessage{
+		ToUser:      openId,
+		TemplateId:  conf.TemplateId,
+		URL:         url,
+		ClientMsgId: hex.EncodeToString(sum[:16]),
+		Data:        data,
+	}, nil
+}
+
+func executeTemplate(name, text string, ctx TemplateContext) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(text)
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err := t.Execute(&buf, ctx); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// templateRecipients returns the OpenIDs of the recipients of template messages.
+func templateRecipients() ([]string, error) {
+	if Config.Wechat.Template.AllFollowers {
+		return wechat.GetAllFollowers(wechatClient)
+	}
+
+	return wechat.GetAllTagFollowers(wechatClient, Config.Wechat.Template.TagId)
+}
+
+// deliveryChunk is the number of deliveries of template messages recorded at once.
+const deliveryChunk = 100
+
+// SendTemplateMessages sends a as template messages to the recipients one by one,
+// and records the delivery to each of them. Recipients who have got the message
+// of the day are skipped, so it's safe to run again after failures. Deliveries are
+// recorded by chunks to avoid saving the store for each message, so the messages
+// of a chunk lost on a crash are sent again. WeChat only deduplicates them by
+// their ClientMsgId for about 10 minutes, those duplicates are accepted knowingly.
+func SendTemplateMessages(now time.Time, a *article.Article) (sent, failed int, err error) {
+	recipients, err := templateRecipients()
+
+	if err != nil {
+		return
+	}
+
+	ctx := NewTemplateContext(now, a)
+	s := store.Default()
+
+	var deliveries []store.Delivery
+
+	defer func() {
+		if e := s.AddDeliveries(deliveries...); e != nil && err == nil {
+			err = e
+		}
+	}()
+
+	for _, openId := range recipients {
+		d, ok, err := s.DeliveryOf(ctx.Date, ChannelTemplate, openId)
+
+		if err != nil {
+			return sent, failed, err
+		}
+
+		if ok && d.Status != store.DeliveryFailed {
+			continue
+		}
+
+		message, err := NewTemplateMessage(ctx, openId)
+
+		if err != nil {
+			return sent, failed, err
+		}
+
+		d = store.Delivery{
+			Date:      ctx.Date,
+			Channel:   ChannelTemplate,
+			Recipient: openId,
+			Status:    store.DeliveryPending,
+			At:        time.Now(),
+		}
+
+		d.MsgId, err = wechat.SendTemplateMessage(wechatClient, message)
+
+		if err != nil {
+			log.WithError(err).WithField("openid", openId).Error("send template message")
+
+			d.Status = store.DeliveryFailed
+			d.Error = err.Error()
+			failed++
+		} else {
+			sent++
+		}
+
+		deliveries = append(deliveries, d)
+
+		if len(deliveries) == deliveryChunk {
+			err := s.AddDeliveries(deliveries...)
+			deliveries = nil
+
+			if err != nil {
+				return sent, failed, err
+			}
+		}
+	}
+
+	return sent, failed, nil
+}
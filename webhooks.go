@@ -0,0 +1,270 @@
+package progressbar201X
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/apex/log"
+
+	. "github.com/sqrthree/progressbar201X/internal/config"
+	"github.com/sqrthree/progressbar201X/internal/publish"
+	"github.com/sqrthree/progressbar201X/internal/store"
+	"github.com/sqrthree/progressbar201X/internal/webhook"
+)
+
+// PublisherWebhook is the name of the publisher of `Config.Webhook.Endpoints`.
+const PublisherWebhook = "webhook"
+
+// ChannelWebhook is the channel of deliveries to webhooks, recipients are the names of endpoints.
+const ChannelWebhook = "webhook"
+
+// EventProgress is the event of the daily progress.
+const EventProgress = "progress"
+
+// WebhookPayload is the body posted to webhooks, and the data of templated bodies.
+type WebhookPayload struct {
+	Event string `json:"event"`
+	Date  string `json:"date"`
+	Year  int    `json:"year"`
+	// Progress is the number of the progress, e.g. 50, and Percent is formatted, e.g. 50%.
+	Progress float64 `json:"progress"`
+	Percent  string  `json:"percent"`
+	Bar      string  `json:"bar"`
+	Title    string  `json:"title"`
+	Digest   string  `json:"digest"`
+	URL      string  `json:"url"`
+}
+
+// NewWebhookPayload returns the payload of p.
+func NewWebhookPayload(p *publish.Post) (WebhookPayload, error) {
+	ctx := NewTemplateContext(p.Time, p.Article)
+
+	url, err := executeTemplate("url", Config.Webhook.URL, ctx)
+
+	if err != nil {
+		return WebhookPayload{}, err
+	}
+
+	return WebhookPayload{
+		Event:    EventProgress,
+		Date:     ctx.Date,
+		Year:     ctx.Year,
+		Progress: p.Article.Data.Progress,
+		Percent:  ctx.Progress,
+		Bar:      ctx.Bar,
+		Title:    ctx.Title,
+		Digest:   ctx.Digest,
+		URL:      url,
+	}, nil
+}
+
+var webhookFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// webhookEndpoint is an endpoint with the template of its body.
+type webhookEndpoint struct {
+	webhook.Endpoint
+	Body string
+}
+
+// body returns the body of the payload to the endpoint, rendered by the template
+// of the endpoint if there is one.
+func (e webhookEndpoint) body(payload WebhookPayload) ([]byte, error) {
+	if e.Body == "" {
+		return json.Marshal(payload)
+	}
+
+	t, err := template.New(e.Name).Funcs(webhookFuncs).Option("missingkey=error").Parse(e.Body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	if err := t.Execute(&buf, payload); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// webhookPublisher posts the payload to `Config.Webhook.Endpoints`.
+type webhookPublisher struct {
+	endpoints []webhookEndpoint
+	sender    *webhook.Sender
+}
+
+func newWebhookPublisher() (*webhookPublisher, error) {
+	conf := Config.Webhook
+
+	if len(conf.Endpoints) == 0 {
+		return nil, errors.New("no endpoints of webhooks to publish to")
+	}
+
+	w := &webhookPublisher{
+		sender: &webhook.Sender{
+			HttpClient: &http.Client{Timeout: time.Duration(conf.Timeout) * time.Second},
+			Attempts:   conf.Attempts,
+			Backoff:    time.Duration(conf.Backoff) * time.Second,
+		},
+	}
+
+	names := map[string]bool{}
+
+	for _, e := range conf.Endpoints {
+		if e.Name == "" || e.URL == "" {
+			return nil, errors.New("an endpoint of webhooks needs a name and a URL")
+		}
+
+		if names[e.Name] {
+			return nil, fmt.Errorf("duplicate endpoint of webhooks %q", e.Name)
+		}
+
+		names[e.Name] = true
+
+		w.endpoints = append(w.endpoints, webhookEndpoint{
+			Endpoint: webhook.Endpoint{
+				Name:        e.Name,
+				URL:         e.URL,
+				ContentType: e.ContentType,
+				Secret:      e.Secret,
+				Headers:     e.Headers,
+			},
+			Body: e.Body,
+		})
+	}
+
+	return w, nil
+}
+
+func (w *webhookPublisher) Name() string {
+	return PublisherWebhook
+}
+
+// Prepare renders the bodies to all endpoints to check the templates, the ref is the date.
+func (w *webhookPublisher) Prepare(p *publish.Post) (string, error) {
+	payload, err := NewWebhookPayload(p)
+
+	if err != nil {
+		return "", err
+	}
+
+	for _, e := range w.endpoints {
+		if _, err := e.body(payload); err != nil {
+			return "", err
+		}
+	}
+
+	return p.Date(), nil
+}
+
+// Preview posts the payload to the endpoint named to only.
+func (w *webhookPublisher) Preview(p *publish.Post, ref, to string) error {
+	for _, e := range w.endpoints {
+		if e.Name != to {
+			continue
+		}
+
+		_, err := w.send(p, e, "preview#"+e.Name)
+		return err
+	}
+
+	return fmt.Errorf("unknown endpoint of webhooks %q", to)
+}
+
+// Publish posts the payload to the endpoints one by one, and records the delivery to
+// each of them. Endpoints which have got the payload of the day are skipped.
+func (w *webhookPublisher) Publish(p *publish.Post, ref, _ string) (string, error) {
+	s := store.Default()
+	failed := 0
+
+	for _, e := range w.endpoints {
+		d, ok, err := s.DeliveryOf(p.Date(), ChannelWebhook, e.Name)
+
+		if err != nil {
+			return "", err
+		}
+
+		if ok && d.Status == store.DeliverySucceeded {
+			continue
+		}
+
+		d = store.Delivery{
+			Date:      p.Date(),
+			Channel:   ChannelWebhook,
+			Recipient: e.Name,
+			Status:    store.DeliverySucceeded,
+		}
+
+		d.Attempts, err = w.send(p, e, p.Date()+"#"+e.Name)
+		d.At = time.Now()
+
+		if err != nil {
+			log.WithError(err).WithField("endpoint", e.Name).Error("post webhook")
+
+			d.Status = store.DeliveryFailed
+			d.Error = err.Error()
+			failed++
+		}
+
+		if err := s.AddDelivery(d); err != nil {
+			return "", err
+		}
+	}
+
+	if failed > 0 {
+		return "", fmt.Errorf("%d of %d endpoints of webhooks failed", failed, len(w.endpoints))
+	}
+
+	return p.Date(), nil
+}
+
+// Status returns the counts of deliveries to endpoints by status, id is the date.
+func (w *webhookPublisher) Status(id string) (string, error) {
+	return deliverySummary(id, ChannelWebhook)
+}
+
+func (w *webhookPublisher) send(p *publish.Post, e webhookEndpoint, delivery string) (int, error) {
+	payload, err := NewWebhookPayload(p)
+
+	if err != nil {
+		return 0, err
+	}
+
+	body, err := e.body(payload)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return w.sender.Send(e.Endpoint, delivery, body)
+}
+
+// Deliveries returns the deliveries of the date through the channel, or all channels if it's empty.
+func Deliveries(date, channel string) ([]store.Delivery, error) {
+	deliveries, err := store.Default().DeliveriesOf(date)
+
+	if err != nil || channel == "" {
+		return deliveries, err
+	}
+
+	var result []store.Delivery
+
+	for _, d := range deliveries {
+		if d.Channel == channel {
+			result = append(result, d)
+		}
+	}
+
+	return result, nil
+}